@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/audit"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+func runAudit(cfg config.Config, args []string) error {
+	flags, date, target, jsonOutput := newAuditFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	auditLog := audit.NewLog(cfg.Audit.Log)
+	entries, err := auditLog.Query(audit.Filter{Date: *date, Target: *target})
+	if errors.Is(err, audit.ErrInvalidDate) {
+		utils.PrintMessage("ERR041", *date)
+		return err
+	}
+	if err != nil {
+		utils.PrintMessage("ERR009")
+		return err
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "START\tEND\tIDENTITY\tPROFILE\tCLUSTER\tSERVICE\tCONTAINER\tEXIT\tREASON")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			e.StartTime.Local().Format(time.DateTime),
+			e.EndTime.Local().Format(time.DateTime),
+			e.Identity, e.Profile, e.Cluster, e.Service, e.Container, e.ExitStatus, e.Reason)
+	}
+	return w.Flush()
+}
+
+func newAuditFlags(cfg config.Config) (*flag.FlagSet, *string, *string, *bool) {
+	flags := newFlagSet("audit")
+	date := flags.String("date", "", "対象日（YYYY-MM-DD）")
+	target := flags.String("target", "", "対象のクラスター名・サービス名・タスク ARN・コンテナ名（部分一致）")
+	jsonOutput := flags.Bool("json", false, "JSON Lines 形式で出力")
+	langFlag(flags)
+	colorFlag(flags)
+	return flags, date, target, jsonOutput
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+func runBookmark(cfg config.Config, args []string) error {
+	if len(args) <= 0 {
+		utils.PrintMessage("ERR017")
+		return fmt.Errorf("bookmark subcommand is required")
+	}
+	switch args[0] {
+	case "add":
+		return addBookmark(cfg, args[1:])
+	case "list":
+		return listBookmarks(cfg)
+	case "rm":
+		return removeBookmark(args[1:])
+	default:
+		utils.PrintMessage("ERR017")
+		return fmt.Errorf("unknown bookmark subcommand: %s", args[0])
+	}
+}
+
+func addBookmark(cfg config.Config, args []string) error {
+	flags, opts := newBookmarkAddFlags(cfg)
+	name, err := parseWithName(flags, args, "ERR017")
+	if err != nil {
+		return err
+	}
+
+	awsConfig, _, tgt, err := chooseTarget(opts)
+	if err != nil {
+		return err
+	}
+	bookmark := config.Target{
+		Profile:   awshelper.ResolveProfile(opts.profile),
+		Region:    awsConfig.Region,
+		Cluster:   tgt.Cluster,
+		Service:   tgt.Service,
+		Container: tgt.Container,
+		Pick:      opts.pick,
+	}
+	if opts.command != cfg.Command {
+		bookmark.Command = opts.command
+	}
+	if err := config.SaveTarget(findConfigFile(), name, bookmark); err != nil {
+		utils.PrintMessage("ERR019")
+		return err
+	}
+	utils.PrintMessage("INF025", name)
+	return nil
+}
+
+func newBookmarkAddFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("bookmark add")
+	opts := newOptions(flags, cfg)
+	return flags, opts
+}
+
+func listBookmarks(cfg config.Config) error {
+	names := make([]string, 0, len(cfg.Targets))
+	for name := range cfg.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPROFILE\tREGION\tCLUSTER\tSERVICE\tCONTAINER\tCOMMAND\tPICK")
+	for _, name := range names {
+		t := cfg.Targets[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", name, t.Profile, t.Region, t.Cluster, t.Service, t.Container, t.Command, t.Pick)
+	}
+	return w.Flush()
+}
+
+func removeBookmark(args []string) error {
+	if len(args) != 1 {
+		utils.PrintMessage("ERR017")
+		return fmt.Errorf("bookmark name is required")
+	}
+	removed, err := config.RemoveTarget(findConfigFile(), args[0])
+	if err != nil {
+		utils.PrintMessage("ERR019")
+		return err
+	}
+	if !removed {
+		utils.PrintMessage("ERR020", args[0])
+		return fmt.Errorf("bookmark %s not found", args[0])
+	}
+	utils.PrintMessage("INF026", args[0])
+	return nil
+}
+
+// 名前の前後どちらにもフラグを指定できるよう、名前以降を再度解析する
+func parseWithName(flags *flag.FlagSet, args []string, usage string) (string, error) {
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+	if flags.NArg() <= 0 {
+		utils.PrintMessage(usage)
+		return "", fmt.Errorf("%s: name is required", flags.Name())
+	}
+	name := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (opts *options) useBookmark(flags *flag.FlagSet) error {
+	name := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		return err
+	}
+	bookmark, ok := opts.config.Targets[name]
+	if !ok {
+		utils.PrintMessage("ERR020", name)
+		return fmt.Errorf("bookmark %s not found", name)
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	fields := []struct {
+		flag  string
+		value string
+		dest  *string
+	}{
+		{"p", bookmark.Profile, &opts.profile},
+		{"region", bookmark.Region, &opts.region},
+		{"cluster", bookmark.Cluster, &opts.target.Cluster},
+		{"service", bookmark.Service, &opts.target.Service},
+		{"container", bookmark.Container, &opts.target.Container},
+		{"command", bookmark.Command, &opts.command},
+		{"pick", bookmark.Pick, &opts.pick},
+	}
+	for _, f := range fields {
+		if f.value != "" && !set[f.flag] {
+			*f.dest = f.value
+		}
+	}
+	opts.pick = awshelper.DefaultPick(opts.pick)
+	return nil
+}
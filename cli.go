@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+type command struct {
+	name string
+	run  func(cfg config.Config, args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"exec", runExec},
+		{"ls", runLs},
+		{"cp", runCp},
+		{"port-forward", runPortForward},
+		{"logs", runLogs},
+		{"describe", runDescribe},
+		{"find", runFind},
+		{"doctor", runDoctor},
+		{"whoami", runWhoami},
+		{"version", runVersion},
+		{"history", runHistory},
+		{"bookmark", runBookmark},
+		{"audit", runAudit},
+		{"config", runConfig},
+		{"completion", runCompletion},
+		{"help", runHelp},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// 使い方と説明はサブコマンド名から help.<名前> と usage.<名前> のラベルで引く
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	key := strings.Fields(name)[0]
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n%s\n\n", utils.Label("usage."+key), utils.Label("help."+key))
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func runHelp(cfg config.Config, args []string) error {
+	if len(args) > 0 {
+		if c, ok := findCommand(args[0]); ok && c.name != "help" {
+			return c.run(cfg, []string{"-h"})
+		}
+	}
+	printCommands(flag.CommandLine.Output())
+	return nil
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintf(w, "%s\n\n%s\n", utils.Label("help.usage"), utils.Label("help.commands"))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.name, utils.Label("help."+c.name))
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%s\n", utils.Label("help.more"))
+}
@@ -2,133 +2,423 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"os/exec"
-	"os/signal"
-	"syscall"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/gajirou/fexec/pkg/audit"
 	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/completion"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/history"
+	"github.com/gajirou/fexec/pkg/output"
+	"github.com/gajirou/fexec/pkg/policy"
+	"github.com/gajirou/fexec/pkg/probe"
+	"github.com/gajirou/fexec/pkg/recorder"
+	"github.com/gajirou/fexec/pkg/session"
 	"github.com/gajirou/fexec/pkg/utils"
 )
 
+const (
+	ssmPlugin         = "session-manager-plugin"
+	taskRunning       = "RUNNING"
+	maxReconnect      = 5
+	reconnectInterval = 2 * time.Second
+)
+
+type options struct {
+	profile    string
+	region     string
+	output     string
+	command    string
+	pick       string
+	record     string
+	reason     string
+	escapeChar string
+	reconnect  bool
+	simple     bool
+	noAuto     bool
+	last       bool
+	allTasks   bool
+	parallel   int
+	target     target
+	config     config.Config
+}
+
+func newOptions(flags *flag.FlagSet, cfg config.Config) *options {
+	opts := globalOptions(flags, cfg)
+	opts.targetFlags(flags)
+	opts.selectFlags(flags)
+	opts.execFlags(flags)
+	return opts
+}
+
+// 全てのサブコマンドで共通のパラメータ
+func globalOptions(flags *flag.FlagSet, cfg config.Config) *options {
+	opts := &options{config: cfg}
+	flags.StringVar(&opts.profile, "p", cfg.Profile, "利用プロファイル名")
+	flags.StringVar(&opts.region, "region", cfg.Region, "利用リージョン（未指定の場合はプロファイルの設定値）")
+	flags.StringVar(&opts.output, "o", output.Table, "出力形式（table / json / yaml）")
+	flags.BoolVar(&verbose, "verbose", false, "AWS API のエラーコード・リクエスト ID・原因を表示")
+	langFlag(flags)
+	colorFlag(flags)
+	return opts
+}
+
+func (opts *options) targetFlags(flags *flag.FlagSet) {
+	flags.StringVar(&opts.target.Cluster, "cluster", "", "接続先のクラスター名")
+	flags.StringVar(&opts.target.Service, "service", "", "接続先のサービス名")
+	flags.StringVar(&opts.target.Task, "task", "", "接続先のタスク ID")
+	flags.StringVar(&opts.target.Container, "container", "", "接続先のコンテナ名")
+}
+
+func (opts *options) selectFlags(flags *flag.FlagSet) {
+	flags.StringVar(&opts.pick, "pick", "", "タスクの選択方法（prompt / first / random / newest / oldest / healthy / az=<ゾーン> / ip=<アドレス> / revision=latest をカンマ区切りで指定）")
+	flags.BoolVar(&opts.simple, "simple", false, "全画面表示ではなく順番に選択するプロンプトを利用")
+	flags.BoolVar(&opts.noAuto, "no-auto", false, "候補が一つの場合やアプリケーションのコンテナが一つの場合も選択を省略しない")
+}
+
+func (opts *options) execFlags(flags *flag.FlagSet) {
+	flags.StringVar(&opts.command, "command", opts.config.Command, "コンテナで実行するコマンド")
+	flags.StringVar(&opts.record, "record", "", "セッションを記録する asciinema v2 形式のファイル名")
+	opts.reasonFlag(flags)
+	flags.BoolVar(&opts.reconnect, "reconnect", false, "異常切断時にタスクが稼働中であれば再接続")
+	flags.StringVar(&opts.escapeChar, "escape", "~", "エスケープ文字（none で無効化）")
+}
+
+func (opts *options) reasonFlag(flags *flag.FlagSet) {
+	flags.StringVar(&opts.reason, "reason", "", "接続理由（監査ログに記録）")
+}
+
+func (opts *options) printer() output.Printer {
+	return output.Printer{Format: opts.output}
+}
+
 func Run() error {
-	profile := flag.String("p", "default", "利用プロファイル名")
-	flag.Parse()
+	err := run()
+	if err != nil {
+		printError(err)
+	}
+	return err
+}
 
-	ssmPlugin := "session-manager-plugin"
-	_, err := exec.LookPath(ssmPlugin)
+func run() error {
+	utils.SetLang(utils.DetectLang(os.Getenv))
+	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == completion.Command {
+		return runComplete(cfg, args[1:])
+	}
+	if len(args) > 0 {
+		if c, ok := findCommand(args[0]); ok {
+			return c.run(cfg, args[1:])
+		}
+	}
+	return runExec(cfg, args)
+}
 
+// サブコマンドを省略した場合も exec として扱い、引数はブックマーク名とする
+func runExec(cfg config.Config, args []string) error {
+	flags, opts := newExecFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if opts.allTasks {
+		if opts.target.Task != "" || opts.last || opts.parallel < 1 {
+			flags.Usage()
+			return fmt.Errorf("invalid arguments for -all-tasks")
+		}
+		return executeAll(opts, flags.Args())
+	}
+
+	if flags.NArg() > 0 {
+		if err := opts.useBookmark(flags); err != nil {
+			return err
+		}
+	}
+
+	if opts.last {
+		historyStore := history.NewStore(findHistoryFile())
+		entry, ok, err := historyStore.Last()
+		if err != nil {
+			utils.PrintMessage("ERR015")
+			return err
+		}
+		if !ok {
+			utils.PrintMessage("INF022")
+			return errNotFound
+		}
+		opts.useHistory(entry)
+	}
+	return execute(opts)
+}
+
+func newExecFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("exec")
+	usage := flags.Usage
+	flags.Usage = func() {
+		printCommands(flags.Output())
+		fmt.Fprintln(flags.Output())
+		usage()
+	}
+	opts := newOptions(flags, cfg)
+	flags.BoolVar(&opts.last, "last", false, "前回と同じサービス・コンテナに接続")
+	flags.BoolVar(&opts.allTasks, "all-tasks", false, "サービスの稼働中の全てのタスクでコマンドを実行（-- の後にコマンドを指定）")
+	flags.IntVar(&opts.parallel, "parallel", defaultParallel, "-all-tasks で同時に実行するタスクの数")
+	return flags, opts
+}
+
+func loadAWSConfig(opts *options) (aws.Config, error) {
 	configService := awshelper.NewConfigService()
-	awsConfig, err := configService.FindAWSCredential(*profile)
+	awsConfig, err := configService.FindAWSCredential(opts.profile)
 	if err != nil {
 		utils.PrintMessage("ERR002")
-		return err
+		return awsConfig, errors.Join(errAuth, err)
+	}
+	if opts.region != "" {
+		awsConfig.Region = opts.region
 	}
 	if awsConfig.Region == "" {
 		utils.PrintMessage("INF001")
-		return nil
+		return awsConfig, errAuth
 	}
+	return awsConfig, nil
+}
 
-	ecsService := awshelper.EcsService{}
+func newEcsService(awsConfig aws.Config, opts *options) *awshelper.EcsService {
+	ecsService := &awshelper.EcsService{MaxCount: opts.config.MaxCount}
 	ecsService.SetEcsClient(awsConfig)
-	clusters, err := ecsService.GetClusters()
+	return ecsService
+}
+
+func chooseTarget(opts *options) (aws.Config, *awshelper.EcsService, target, error) {
+	awsConfig, err := loadAWSConfig(opts)
 	if err != nil {
-		utils.PrintMessage("ERR003")
-		return err
+		return awsConfig, nil, target{}, err
+	}
+	ecsService := newEcsService(awsConfig, opts)
+	tgt, err := selectTarget(ecsService, awsConfig, opts)
+	return awsConfig, ecsService, tgt, err
+}
+
+func findPlugin() (string, error) {
+	if _, err := exec.LookPath(ssmPlugin); err != nil {
+		utils.PrintMessage("ERR001")
+		return "", errors.Join(errPluginMissing, err)
 	}
-	cluster, err := utils.ScreenDraw(clusters, "clusters")
+	return ssmPlugin, nil
+}
+
+func execute(opts *options) error {
+	ssmPlugin, err := findPlugin()
 	if err != nil {
-		utils.PrintMessage("ERR999")
 		return err
 	}
-	if cluster == "" {
-		utils.PrintMessage("INF002")
-		return nil
-	}
 
-	services, err := ecsService.GetServices(cluster)
+	awsConfig, ecsService, tgt, err := chooseTarget(opts)
 	if err != nil {
-		utils.PrintMessage("ERR004")
 		return err
 	}
-	if services == nil {
-		utils.PrintMessage("INF003")
-		return nil
-	}
-	service, err := utils.ScreenDraw(services, "services")
-	if err != nil {
-		utils.PrintMessage("ERR999")
+	cluster, service, task, container := tgt.Cluster, tgt.Service, tgt.Task, tgt.Container
+
+	if err := requireReason(opts, cluster, service); err != nil {
 		return err
 	}
-	if service == "" {
-		utils.PrintMessage("INF004")
-		return nil
+	remote := newRemoteCommand(ssmPlugin, awsConfig, ecsService, tgt)
+	command := opts.command
+	if command == probe.Auto {
+		command = remote.defaultCommand()
 	}
+	if opts.reason != "" {
+		command = remote.withEnv(command, map[string]string{"FEXEC_REASON": opts.reason})
+	}
+
+	execCommand := remote.command(command)
 
-	tasks, err := ecsService.GetTasks(cluster, service)
+	execCmd, err := ecsService.ExecuteContainer(cluster, task, container, execCommand)
 	if err != nil {
-		utils.PrintMessage("ERR005")
 		return err
 	}
-	if tasks == nil {
-		utils.PrintMessage("INF005")
-		return nil
+	historyStore := history.NewStore(findHistoryFile())
+	err = historyStore.Add(history.Entry{
+		Profile:   awshelper.ResolveProfile(opts.profile),
+		Region:    awsConfig.Region,
+		Cluster:   cluster,
+		Service:   service,
+		Task:      task,
+		Container: container,
+		Time:      time.Now(),
+	})
+	if err != nil {
+		utils.PrintMessage("ERR016")
 	}
-	task, err := utils.ScreenDraw(tasks, "tasks")
+	pluginArgs, err := sessionArgs(execCmd, awsConfig.Region)
 	if err != nil {
 		utils.PrintMessage("ERR999")
 		return err
 	}
-	if task == "" {
-		utils.PrintMessage("INF006")
-		return nil
+	targetName := strings.Join([]string{cluster, service, task, container}, "/")
+	ses := session.Session{
+		Plugin:     ssmPlugin,
+		Args:       pluginArgs,
+		Title:      "fexec: " + targetName,
+		ExitMarker: remote.marker,
 	}
 
-	containers, err := ecsService.GetContainers(cluster, task)
+	auditor := newAuditor(awsConfig, opts)
+
+	handler := &escapeHandler{
+		awsConfig:  awsConfig,
+		profile:    awshelper.ResolveProfile(opts.profile),
+		plugin:     ssmPlugin,
+		ecsService: ecsService,
+		cluster:    cluster,
+		task:       task,
+		container:  container,
+		target:     targetName,
+		identity:   auditor.identity.Arn,
+	}
+	defer handler.close()
+	ses.Escape, err = newEscape(opts.escapeChar, handler)
 	if err != nil {
-		utils.PrintMessage("ERR006")
+		utils.PrintMessage("ERR014")
 		return err
 	}
-	if containers == nil {
-		utils.PrintMessage("INF007")
+
+	castFile := findCastFile(opts.record, opts.config.Audit.RecordDir, cluster, task)
+	if castFile != "" {
+		rec, err := recorder.Create(castFile, targetName)
+		if err != nil {
+			utils.PrintMessage("ERR007")
+			return err
+		}
+		ses.Recorder = rec
+		utils.PrintMessage("INF010", castFile)
+	}
+
+	startTime := time.Now()
+	handler.startTime = startTime
+	runErr := ses.Run()
+	for attempt := 1; opts.reconnect && ses.NeedsReconnect(runErr); attempt++ {
+		if attempt > maxReconnect {
+			utils.PrintMessage("INF016", maxReconnect)
+			break
+		}
+		status, err := ecsService.GetTaskStatus(cluster, task)
+		if err != nil || status != taskRunning {
+			utils.PrintMessage("INF015", task)
+			break
+		}
+		utils.PrintMessage("INF014", targetName)
+		time.Sleep(reconnectInterval)
+		// 監査ログとセッションの記録には最後に接続したセッションを使う
+		retryCmd, err := ecsService.ExecuteContainer(cluster, task, container, execCommand)
+		if err != nil {
+			printError(err)
+			break
+		}
+		if ses.Args, err = sessionArgs(retryCmd, awsConfig.Region); err != nil {
+			break
+		}
+		execCmd = retryCmd
+		runErr = ses.Run()
+	}
+	if ses.Recorder != nil {
+		ses.Recorder.Close()
+	}
+	endTime := time.Now()
+	exitStatus := session.ExitStatus(runErr)
+	remoteStatus, remoteExited := ses.RemoteExitCode()
+	if remoteExited {
+		exitStatus = remoteStatus
+	}
+	utils.PrintMessage("INF013", targetName, endTime.Sub(startTime).Round(time.Second), exitStatus)
+	auditor.record(audit.Entry{
+		Cluster:    cluster,
+		Service:    service,
+		TaskArn:    aws.ToString(execCmd.TaskArn),
+		Container:  container,
+		Command:    command,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		ExitStatus: exitStatus,
+	}, aws.ToString(execCmd.Session.SessionId), castFile)
+	auditor.wait()
+	if remoteExited {
+		if remoteStatus != 0 {
+			return &remoteExitError{status: remoteStatus}
+		}
 		return nil
 	}
-	container, err := utils.ScreenDraw(containers, "containers")
-	if err != nil {
+	if runErr != nil {
 		utils.PrintMessage("ERR999")
-		return err
+		return runErr
 	}
-	if container == "" {
-		utils.PrintMessage("INF008")
+
+	return nil
+}
+
+// 保護対象のサービスでは接続理由を必須とし、指定がなければ入力させる
+func requireReason(opts *options, cluster string, service string) error {
+	pol := loadPolicy(opts.config)
+	if !pol.IsProtected(cluster, service) {
 		return nil
 	}
-
-	execCmd, err := ecsService.ExecuteContainer(cluster, task, container)
-	if err != nil {
-		utils.PrintMessage("INF009")
-		return err
+	if opts.reason != "" {
+		if err := pol.ValidateReason(opts.reason); err != nil {
+			utils.PrintMessage("ERR012", pol.Pattern())
+			return err
+		}
+		return nil
 	}
-	execSes, err := json.MarshalIndent(execCmd.Session, "", " ")
+	reason, err := utils.ScreenInput("reason", pol.ValidateReason)
+	opts.reason = reason
 	if err != nil {
 		utils.PrintMessage("ERR999")
 		return err
 	}
-	cmd := exec.Command("session-manager-plugin", string(execSes), awsConfig.Region, "StartSession")
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	cmd.Stdin = os.Stdin
+	if opts.reason == "" {
+		utils.PrintMessage("INF012")
+		return errCancelled
+	}
+	return nil
+}
 
-	signal.Ignore(os.Interrupt, syscall.SIGTERM)
-	defer signal.Reset(os.Interrupt, syscall.SIGTERM)
-	
-	if err := cmd.Run(); err != nil {
-		utils.PrintMessage("ERR999")
-		return err
+func findCastFile(record string, recordDir string, cluster string, task string) string {
+	if record != "" {
+		return record
+	}
+	if recordDir == "" {
+		return ""
 	}
+	fileName := fmt.Sprintf("%s_%s_%s.cast", time.Now().Format("20060102-150405"), cluster, task)
+	return filepath.Join(recordDir, fileName)
+}
 
-	return nil
+// ポリシーファイルは設定ファイルと同じ形式で、ユーザー設定の一部として読み込む
+func findPolicyFile() string {
+	return filepath.Join(utils.ConfigDir(), "policy.yaml")
+}
+
+func loadPolicy(cfg config.Config) policy.Policy {
+	return policy.Policy{Protected: cfg.Protected, ReasonPattern: cfg.ReasonPattern}
+}
+
+func sessionArgs(execCmd *ecs.ExecuteCommandOutput, region string) ([]string, error) {
+	execSes, err := json.MarshalIndent(execCmd.Session, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(execSes), region, "StartSession"}, nil
 }
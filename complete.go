@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/completion"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/output"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+var (
+	shells = []string{"bash", "zsh", "fish"}
+	// サブコマンドを持つコマンドは、パラメータを受け付けるサブコマンドのみパラメータを補完する
+	nestedCommands = map[string][]string{
+		"bookmark": {"add", "list", "rm"},
+		"config":   {"view"},
+	}
+)
+
+// 補完ではコマンドを実行せずにパラメータを取得するため、パラメータの定義のみを行う関数を引く
+func commandFlags(cfg config.Config, name string) (*flag.FlagSet, bool) {
+	newFlags := map[string]func(cfg config.Config) *flag.FlagSet{
+		"exec":         func(cfg config.Config) *flag.FlagSet { flags, _ := newExecFlags(cfg); return flags },
+		"ls":           func(cfg config.Config) *flag.FlagSet { flags, _, _ := newLsFlags(cfg); return flags },
+		"cp":           func(cfg config.Config) *flag.FlagSet { flags, _ := newCpFlags(cfg); return flags },
+		"port-forward": func(cfg config.Config) *flag.FlagSet { flags, _ := newPortForwardFlags(cfg); return flags },
+		"logs":         func(cfg config.Config) *flag.FlagSet { flags, _, _, _ := newLogsFlags(cfg); return flags },
+		"describe":     func(cfg config.Config) *flag.FlagSet { flags, _, _ := newDescribeFlags(cfg); return flags },
+		"find":         func(cfg config.Config) *flag.FlagSet { flags, _ := newFindFlags(cfg); return flags },
+		"doctor":       func(cfg config.Config) *flag.FlagSet { flags, _ := newDoctorFlags(cfg); return flags },
+		"whoami":       func(cfg config.Config) *flag.FlagSet { flags, _ := newWhoamiFlags(cfg); return flags },
+		"version":      func(cfg config.Config) *flag.FlagSet { flags, _ := newVersionFlags(cfg); return flags },
+		"history":      func(cfg config.Config) *flag.FlagSet { flags, _ := newHistoryFlags(cfg); return flags },
+		"bookmark add": func(cfg config.Config) *flag.FlagSet { flags, _ := newBookmarkAddFlags(cfg); return flags },
+		"audit":        func(cfg config.Config) *flag.FlagSet { flags, _, _, _ := newAuditFlags(cfg); return flags },
+		"config view":  func(cfg config.Config) *flag.FlagSet { flags, _ := newConfigViewFlags(cfg); return flags },
+		"completion":   newCompletionFlags,
+	}[name]
+	if newFlags == nil {
+		return nil, false
+	}
+	flags := newFlags(cfg)
+	flags.SetOutput(io.Discard)
+	return flags, true
+}
+
+func runCompletion(cfg config.Config, args []string) error {
+	flags := newCompletionFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	script, err := completion.Script(flags.Arg(0))
+	if err != nil {
+		flags.Usage()
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
+
+func newCompletionFlags(cfg config.Config) *flag.FlagSet {
+	flags := newFlagSet("completion")
+	return flags
+}
+
+// 補完スクリプトから呼び出され、候補を一行ずつ出力する。エラーは補完の妨げになるため出力しない
+func runComplete(cfg config.Config, args []string) error {
+	if len(args) <= 0 {
+		args = []string{""}
+	}
+	for _, candidate := range complete(cfg, args[:len(args)-1], args[len(args)-1]) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+func complete(cfg config.Config, words []string, current string) []string {
+	if len(words) <= 0 && !strings.HasPrefix(current, "-") {
+		return completion.Filter(append(commandNames(), bookmarkNames(cfg)...), current)
+	}
+	c, _ := findCommand("exec")
+	if len(words) > 0 {
+		if found, ok := findCommand(words[0]); ok {
+			c, words = found, words[1:]
+		}
+	}
+	name := c.name
+	if subcommands, ok := nestedCommands[c.name]; ok {
+		if len(words) <= 0 {
+			return completion.Filter(subcommands, current)
+		}
+		name, words = c.name+" "+words[0], words[1:]
+	}
+
+	flags, ok := commandFlags(cfg, name)
+	if !ok {
+		if strings.HasPrefix(current, "-") {
+			return nil
+		}
+		return completion.Filter(positionals(cfg, name, len(words)), current)
+	}
+	// 入力済みのプロファイルやクラスターを候補の取得に使うため、入力中の単語より前を解析する
+	flags.Parse(words)
+	if len(words) > 0 {
+		if f := valueFlag(flags, words[len(words)-1]); f != "" {
+			return completion.Filter(flagValues(cfg, flags, f), current)
+		}
+	}
+	if strings.HasPrefix(current, "-") {
+		dashes := "-"
+		if strings.HasPrefix(current, "--") {
+			dashes = "--"
+		}
+		var names []string
+		flags.VisitAll(func(f *flag.Flag) {
+			names = append(names, dashes+f.Name)
+		})
+		return completion.Filter(names, current)
+	}
+	return completion.Filter(positionals(cfg, name, flags.NArg()), current)
+}
+
+func valueFlag(flags *flag.FlagSet, word string) string {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return ""
+	}
+	name := strings.TrimLeft(word, "-")
+	f := flags.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return ""
+	}
+	return name
+}
+
+func flagValues(cfg config.Config, flags *flag.FlagSet, name string) []string {
+	switch name {
+	case "p":
+		return awshelper.ListProfiles()
+	case "o":
+		return []string{output.Table, output.JSON, output.YAML}
+	case "pick":
+		return []string{awshelper.PickPrompt, awshelper.PickFirst, awshelper.PickRandom, awshelper.PickNewest, awshelper.PickOldest, awshelper.PickHealthy, awshelper.PickLatest}
+	case "lang":
+		return []string{utils.LangJa, utils.LangEn}
+	case "cluster", "service", "task", "container":
+		value := func(name string) string {
+			if f := flags.Lookup(name); f != nil {
+				return f.Value.String()
+			}
+			return ""
+		}
+		opts := &options{
+			config:  cfg,
+			profile: value("p"),
+			region:  value("region"),
+			target:  target{Cluster: value("cluster"), Service: value("service"), Task: value("task")},
+		}
+		return liveValues(opts, name)
+	}
+	return nil
+}
+
+func liveValues(opts *options, kind string) []string {
+	tgt := opts.target
+	key := strings.Join([]string{kind, awshelper.ResolveProfile(opts.profile), opts.region, tgt.Cluster, tgt.Service, tgt.Task}, "/")
+	cache := completion.NewCache(filepath.Join(utils.CacheDir(), "completion.json"), completion.DefaultTTL)
+	if values, ok := cache.Get(key); ok {
+		return values
+	}
+
+	configService := awshelper.NewConfigService()
+	awsConfig, err := configService.FindAWSCredential(opts.profile)
+	if err != nil {
+		return nil
+	}
+	if opts.region != "" {
+		awsConfig.Region = opts.region
+	}
+	if awsConfig.Region == "" || (kind != "cluster" && tgt.Cluster == "") {
+		return nil
+	}
+	ecsService := newEcsService(awsConfig, opts)
+	var values []string
+	switch kind {
+	case "cluster":
+		values, err = ecsService.GetClusters()
+	case "service":
+		values, err = ecsService.GetServices(tgt.Cluster)
+	case "task":
+		values, err = ecsService.GetTasks(tgt.Cluster, tgt.Service)
+	case "container":
+		task := tgt.Task
+		if task == "" {
+			tasks, err := ecsService.GetTasks(tgt.Cluster, tgt.Service)
+			if err != nil || len(tasks) <= 0 {
+				return nil
+			}
+			task = tasks[0]
+		}
+		values, err = ecsService.GetContainers(tgt.Cluster, task)
+	}
+	if err != nil {
+		return nil
+	}
+	cache.Set(key, values)
+	return values
+}
+
+func positionals(cfg config.Config, name string, index int) []string {
+	if index > 0 {
+		return nil
+	}
+	switch name {
+	case "exec", "bookmark rm":
+		return bookmarkNames(cfg)
+	case "ls":
+		return []string{"clusters", "services", "tasks", "containers"}
+	case "help":
+		return commandNames()
+	case "completion":
+		return shells
+	}
+	return nil
+}
+
+func commandNames() []string {
+	var names []string
+	for _, c := range commands() {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func bookmarkNames(cfg config.Config) []string {
+	names := make([]string, 0, len(cfg.Targets))
+	for name := range cfg.Targets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/probe"
+	"github.com/gajirou/fexec/pkg/utils"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+func runConfig(cfg config.Config, args []string) error {
+	if len(args) <= 0 || args[0] != "view" {
+		utils.PrintMessage("ERR022")
+		return fmt.Errorf("config subcommand is required")
+	}
+	flags, opts := newConfigViewFlags(cfg)
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	cfg.Profile = opts.profile
+	cfg.Region = opts.region
+	cfg.Command = opts.command
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func newConfigViewFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("config view")
+	opts := newOptions(flags, cfg)
+	return flags, opts
+}
+
+// コンテナの選択を省略する際に除外する、よく使われるサイドカーのコンテナ名
+var defaultSidecars = []string{
+	"datadog-agent",
+	"envoy",
+	"aws-otel-collector",
+	"xray-daemon",
+	"log_router",
+	"aws-guardduty-agent-*",
+	"ecs-service-connect-*",
+}
+
+func defaultConfig() config.Config {
+	return config.Config{
+		Profile:  "default",
+		Command:  probe.Auto,
+		PageSize: utils.DefaultPageSize,
+		MaxCount: awshelper.DefaultMaxCount,
+		Color:    config.ColorAuto,
+		Sidecars: defaultSidecars,
+		Audit: config.Audit{
+			Log: filepath.Join(utils.StateDir(), "audit.log"),
+		},
+	}
+}
+
+func loadConfig() (config.Config, error) {
+	var projectFile string
+	if dir, err := os.Getwd(); err == nil {
+		projectFile = config.FindProjectFile(dir)
+	}
+	cfg, err := config.Resolve(defaultConfig(), []string{findPolicyFile(), findConfigFile()}, projectFile, os.Getenv)
+	// 読み込みのエラーも NO_COLOR や出力先に従って表示するよう、先に色付けを反映する（不正な値は auto として扱う）
+	setColor(cfg.Color)
+	if err == nil && cfg.Lang != "" {
+		err = utils.SetLang(cfg.Lang)
+	}
+	if err != nil {
+		utils.PrintMessage("ERR018", err)
+		return cfg, err
+	}
+	utils.SetPageSize(cfg.PageSize)
+	return cfg, nil
+}
+
+func langFlag(flags *flag.FlagSet) {
+	flags.Func("lang", "メッセージの言語（ja / en）", utils.SetLang)
+}
+
+func colorFlag(flags *flag.FlagSet) {
+	flags.Func("color", "メッセージの色付け（auto / always / never）", func(mode string) error {
+		if err := config.ValidateColor(mode); err != nil {
+			return err
+		}
+		setColor(mode)
+		return nil
+	})
+}
+
+// メッセージは標準エラー出力に出力するため、標準エラー出力が端末かどうかで判定する
+func setColor(mode string) {
+	utils.SetColor(config.UseColor(mode, os.Getenv, term.IsTerminal(int(os.Stderr.Fd()))))
+}
+
+// FEXEC_CONFIG で指定したファイルは環境変数と同じ扱いで重ねるため、ここでは参照しない
+func findConfigFile() string {
+	return filepath.Join(utils.ConfigDir(), "config.yaml")
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/gajirou/fexec/pkg/audit"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+const (
+	remotePrefix   = ":"
+	cpBegin        = "fexec-cp-begin"
+	cpEnd          = "fexec-cp-end"
+	base64LineSize = 76
+)
+
+// リモートのパスは先頭に : を付けて指定する
+func runCp(cfg config.Config, args []string) error {
+	flags, opts := newCpFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	src, dst := flags.Arg(0), flags.Arg(1)
+	remoteSrc, download := strings.CutPrefix(src, remotePrefix)
+	remoteDst, upload := strings.CutPrefix(dst, remotePrefix)
+	if flags.NArg() != 2 || download == upload {
+		flags.Usage()
+		return fmt.Errorf("exactly one of the paths must be remote")
+	}
+
+	plugin, err := findPlugin()
+	if err != nil {
+		return err
+	}
+	awsConfig, ecsService, tgt, err := chooseTarget(opts)
+	if err != nil {
+		return err
+	}
+	if err := requireReason(opts, tgt.Cluster, tgt.Service); err != nil {
+		return err
+	}
+	auditor := newAuditor(awsConfig, opts)
+	remote := newRemoteCommand(plugin, awsConfig, ecsService, tgt)
+	startTime := time.Now()
+	var size int
+	if download {
+		if info, err := os.Stat(dst); err == nil && info.IsDir() {
+			dst = filepath.Join(dst, remoteBase(remoteSrc))
+		}
+		size, err = remote.download(remoteSrc, dst)
+	} else {
+		if remoteDst == "" || strings.HasSuffix(remoteDst, "/") || strings.HasSuffix(remoteDst, `\`) {
+			remoteDst += filepath.Base(src)
+		}
+		size, err = remote.upload(src, remoteDst)
+	}
+	auditor.record(audit.Entry{
+		Cluster:    tgt.Cluster,
+		Service:    tgt.Service,
+		TaskArn:    remote.taskArn,
+		Container:  tgt.Container,
+		Command:    strings.Join([]string{"cp", src, dst}, " "),
+		StartTime:  startTime,
+		EndTime:    time.Now(),
+		ExitStatus: sessionExitStatus(err),
+	}, remote.sessionId, "")
+	auditor.wait()
+	if err != nil {
+		utils.PrintMessage("ERR033", err)
+		return err
+	}
+	utils.PrintMessage("INF027", src, dst, size)
+	return nil
+}
+
+func newCpFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("cp")
+	opts := globalOptions(flags, cfg)
+	opts.targetFlags(flags)
+	opts.selectFlags(flags)
+	opts.reasonFlag(flags)
+	return flags, opts
+}
+
+type remoteCommand struct {
+	plugin     string
+	awsConfig  aws.Config
+	ecsService *awshelper.EcsService
+	target     target
+	platform   awshelper.TaskPlatform
+	shell      string
+	marker     session.ExitMarker
+	taskArn    string
+	sessionId  string
+}
+
+// 終了コードを取得できなかった場合は転送が完了していないものとして扱う
+func (remote *remoteCommand) run(script string, stdin io.Reader, stdout io.Writer) error {
+	command := remote.command(script)
+	tgt := remote.target
+	execCmd, err := remote.ecsService.ExecuteContainer(tgt.Cluster, tgt.Task, tgt.Container, command)
+	if err != nil {
+		return err
+	}
+	remote.taskArn, remote.sessionId = aws.ToString(execCmd.TaskArn), aws.ToString(execCmd.Session.SessionId)
+	pluginArgs, err := sessionArgs(execCmd, remote.awsConfig.Region)
+	if err != nil {
+		return err
+	}
+	ses := session.Session{
+		Plugin:     remote.plugin,
+		Args:       pluginArgs,
+		ExitMarker: remote.marker,
+		Stdin:      stdin,
+		Stdout:     stdout,
+	}
+	runErr := ses.Run()
+	status, ok := ses.RemoteExitCode()
+	if !ok {
+		if runErr != nil {
+			return runErr
+		}
+		return errors.New("the remote command did not report its exit status")
+	}
+	if status != 0 {
+		return &remoteExitError{status: status}
+	}
+	return nil
+}
+
+// リモートは端末として動作するため、エコーを止めた上で base64 の行を送り EOF で終える
+// Windows のコンソールには EOF を送れないため、終了の目印の行まで読み込ませる
+func (remote *remoteCommand) upload(src string, dst string) (int, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return 0, err
+	}
+	newline, eof := "\n", "\x04"
+	script := "stty -echo; base64 -d > " + remote.quote(dst)
+	if remote.platform.Windows() {
+		newline, eof = "\r", cpEnd+"\r"
+		script = windowsUploadScript(remote.quote(dst))
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var input strings.Builder
+	for len(encoded) > 0 {
+		n := min(len(encoded), base64LineSize)
+		input.WriteString(encoded[:n] + newline)
+		encoded = encoded[n:]
+	}
+	input.WriteString(eof)
+	if err := remote.run(script, strings.NewReader(input.String()), io.Discard); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (remote *remoteCommand) download(src string, dst string) (int, error) {
+	var out bytes.Buffer
+	script := fmt.Sprintf("echo %s && base64 %s && echo %s", cpBegin, remote.quote(src), cpEnd)
+	if remote.platform.Windows() {
+		script = windowsDownloadScript(remote.quote(src))
+	}
+	if err := remote.run(script, nil, &out); err != nil {
+		return 0, err
+	}
+	data, err := decodeTransfer(out.String())
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// PowerShell の相対パスは .NET のカレントディレクトリと異なるため、実行中の場所から解決する
+func windowsUploadScript(dst string) string {
+	return "$ErrorActionPreference = 'Stop'; " +
+		"$fexecPath = $ExecutionContext.SessionState.Path.GetUnresolvedProviderPathFromPSPath(" + dst + "); " +
+		"$fexecData = New-Object System.Text.StringBuilder; " +
+		"while (($fexecLine = [Console]::In.ReadLine()) -ne $null -and $fexecLine -ne '" + cpEnd + "') { [void]$fexecData.Append($fexecLine) }; " +
+		"[IO.File]::WriteAllBytes($fexecPath, [Convert]::FromBase64String($fexecData.ToString()))"
+}
+
+func windowsDownloadScript(src string) string {
+	return "$ErrorActionPreference = 'Stop'; " +
+		"$fexecData = [IO.File]::ReadAllBytes((Resolve-Path -LiteralPath " + src + ").ProviderPath); " +
+		"Write-Output '" + cpBegin + "'; " +
+		"[Convert]::ToBase64String($fexecData, 'InsertLineBreaks'); " +
+		"Write-Output '" + cpEnd + "'"
+}
+
+// Windows のパスの区切り文字も考慮してファイル名を取り出す
+func remoteBase(p string) string {
+	return path.Base(strings.ReplaceAll(p, `\`, "/"))
+}
+
+// 開始と終了の目印の間にある base64 の行を復号する
+func decodeTransfer(out string) ([]byte, error) {
+	out = strings.ReplaceAll(out, "\r", "")
+	_, body, found := strings.Cut(out, cpBegin+"\n")
+	if !found {
+		return nil, errors.New("the transfer did not start")
+	}
+	body, _, found = strings.Cut(body, cpEnd+"\n")
+	if !found {
+		return nil, errors.New("the transfer did not complete")
+	}
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\n", ""))
+}
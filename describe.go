@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+// 指定したパラメータのうち最も詳細なリソースを表示する
+func runDescribe(cfg config.Config, args []string) error {
+	flags, opts, tmpl := newDescribeFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	tgt := opts.target
+	if flags.NArg() > 0 || tgt.Cluster == "" || (tgt.Container != "" && tgt.Task == "") {
+		flags.Usage()
+		return fmt.Errorf("missing -cluster or -task for describe")
+	}
+	printer := opts.printer()
+	printer.Template = *tmpl
+	if err := printer.Validate(); err != nil {
+		utils.PrintMessage("ERR032", err)
+		return err
+	}
+
+	awsConfig, err := loadAWSConfig(opts)
+	if err != nil {
+		return err
+	}
+	resource, err := describeResource(newEcsService(awsConfig, opts), tgt)
+	if err != nil {
+		return err
+	}
+	if resource == nil {
+		utils.PrintMessage("INF028")
+		return errNotFound
+	}
+	return printer.PrintFields(os.Stdout, resource)
+}
+
+func newDescribeFlags(cfg config.Config) (*flag.FlagSet, *options, *string) {
+	flags := newFlagSet("describe")
+	opts := globalOptions(flags, cfg)
+	opts.targetFlags(flags)
+	tmpl := flags.String("template", "", "Go テンプレート")
+	return flags, opts, tmpl
+}
+
+func describeResource(ecsService *awshelper.EcsService, tgt target) (interface{}, error) {
+	switch {
+	case tgt.Container != "":
+		containers, err := ecsService.ListContainerInfos(tgt.Cluster, tgt.Task)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			if c.Name == tgt.Container {
+				return c, nil
+			}
+		}
+	case tgt.Task != "":
+		tasks, err := ecsService.DescribeTaskInfos(tgt.Cluster, []string{tgt.Task})
+		if err != nil || len(tasks) <= 0 {
+			return nil, err
+		}
+		return tasks[0], nil
+	case tgt.Service != "":
+		services, err := ecsService.DescribeServiceInfos(tgt.Cluster, []string{tgt.Service})
+		if err != nil || len(services) <= 0 {
+			return nil, err
+		}
+		return services[0], nil
+	default:
+		clusters, err := ecsService.DescribeClusterInfos([]string{tgt.Cluster})
+		if err != nil || len(clusters) <= 0 {
+			return nil, err
+		}
+		return clusters[0], nil
+	}
+	return nil, nil
+}
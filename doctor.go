@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+	"golang.org/x/term"
+)
+
+const (
+	checkOk   = "OK"
+	checkNg   = "NG"
+	checkWarn = "WARN"
+)
+
+type doctor struct {
+	failed int
+}
+
+func (d *doctor) report(label string, detail string, err error) {
+	status := checkOk
+	if err != nil {
+		status, detail = checkNg, err.Error()
+		d.failed++
+	}
+	fmt.Printf("[%s] %s: %s\n", status, utils.Label(label), detail)
+}
+
+// 接続に必要な環境を順に確認し、認証情報が得られない場合は AWS への確認を省略する
+func runDoctor(cfg config.Config, args []string) error {
+	flags, opts := newDoctorFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	d := &doctor{}
+
+	pluginPath, err := exec.LookPath(ssmPlugin)
+	if err == nil {
+		var pluginVersion string
+		pluginVersion, err = session.PluginVersion(pluginPath)
+		if err == nil && !session.SupportsEcsExec(pluginVersion) {
+			err = fmt.Errorf("%s is older than %s", pluginVersion, session.MinPluginVersion)
+		}
+		pluginPath += " " + pluginVersion
+	}
+	d.report("doctor.plugin", pluginPath, err)
+	d.report("doctor.config", findConfigFile(), nil)
+
+	configService := awshelper.NewConfigService()
+	awsConfig, err := configService.FindAWSCredential(opts.profile)
+	d.report("doctor.credential", awshelper.ResolveProfile(opts.profile), err)
+	if err == nil {
+		if opts.region != "" {
+			awsConfig.Region = opts.region
+		}
+		if awsConfig.Region == "" {
+			err = errors.New("region is not set")
+		}
+		d.report("doctor.region", awsConfig.Region, err)
+	}
+	if err == nil {
+		stsService := awshelper.StsService{}
+		stsService.SetStsClient(awsConfig)
+		identity, err := stsService.GetCallerIdentity()
+		d.report("doctor.identity", identity.Arn, err)
+
+		clusters, err := newEcsService(awsConfig, opts).GetClusters()
+		d.report("doctor.ecs", fmt.Sprintf(utils.Label("doctor.clusters"), len(clusters)), err)
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
+		d.report("doctor.terminal", "tty", nil)
+	} else {
+		fmt.Printf("[%s] %s: %s\n", checkWarn, utils.Label("doctor.terminal"), utils.Label("doctor.no_terminal"))
+	}
+
+	if d.failed > 0 {
+		utils.PrintMessage("ERR035", d.failed)
+		return fmt.Errorf("doctor found %d problems", d.failed)
+	}
+	return nil
+}
+
+func newDoctorFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("doctor")
+	opts := globalOptions(flags, cfg)
+	return flags, opts
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+const (
+	exitError         = 70
+	exitCancelled     = 71
+	exitNotFound      = 72
+	exitAuth          = 73
+	exitPermission    = 74
+	exitExecDisabled  = 75
+	exitPluginMissing = 76
+	// fexec の終了コードと重なるリモートの終了コードの置き換え先
+	exitRemoteReserved = 1
+)
+
+var (
+	verbose bool
+
+	errCancelled     = errors.New("cancelled")
+	errNotFound      = errors.New("nothing to select")
+	errAuth          = errors.New("authentication failed")
+	errPluginMissing = errors.New("session-manager-plugin is not installed")
+
+	apiErrorMessages = map[error]string{
+		awshelper.ErrAccessDenied:       "ERR023",
+		awshelper.ErrClusterNotFound:    "ERR024",
+		awshelper.ErrExecDisabled:       "ERR025",
+		awshelper.ErrTargetNotConnected: "ERR026",
+		awshelper.ErrExpiredToken:       "ERR027",
+		awshelper.ErrThrottled:          "ERR028",
+	}
+)
+
+func printError(err error) {
+	var apiErr *awshelper.APIError
+	if !errors.As(err, &apiErr) {
+		return
+	}
+	if label, ok := apiErrorMessages[apiErr.Kind]; ok {
+		utils.PrintMessage(label, apiErr.Action)
+	} else {
+		utils.PrintMessage("ERR029", apiErr.Action, apiErr.Err)
+	}
+	if verbose {
+		utils.PrintMessage("ERR030", apiErr.Code, apiErr.RequestId, apiErr.Err)
+	}
+}
+
+type remoteExitError struct {
+	status int
+}
+
+func (e *remoteExitError) Error() string {
+	return fmt.Sprintf("remote command exited with status %d", e.status)
+}
+
+// 監査ログには fexec の終了コードではなく、リモートのコマンドまたはセッションの終了コードを記録する
+// セッションを開始できなかった場合は -1 とする
+func sessionExitStatus(err error) int {
+	var remoteErr *remoteExitError
+	if errors.As(err, &remoteErr) {
+		return remoteErr.status
+	}
+	return session.ExitStatus(err)
+}
+
+// リモートのコマンドが失敗した場合はその終了コードをそのまま返す
+// ただし fexec の終了コード（70〜76）と区別できるよう、その範囲の終了コードは 1 に置き換える
+func ExitCode(err error) int {
+	var remoteErr *remoteExitError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &remoteErr):
+		if remoteErr.status >= exitError && remoteErr.status <= exitPluginMissing {
+			return exitRemoteReserved
+		}
+		return remoteErr.status
+	case errors.Is(err, errCancelled):
+		return exitCancelled
+	case errors.Is(err, errNotFound):
+		return exitNotFound
+	case errors.Is(err, errAuth), errors.Is(err, awshelper.ErrExpiredToken):
+		return exitAuth
+	case errors.Is(err, awshelper.ErrAccessDenied):
+		return exitPermission
+	case errors.Is(err, awshelper.ErrExecDisabled), errors.Is(err, awshelper.ErrTargetNotConnected):
+		return exitExecDisabled
+	case errors.Is(err, errPluginMissing):
+		return exitPluginMissing
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+type escapeHandler struct {
+	awsConfig  aws.Config
+	profile    string
+	plugin     string
+	ecsService *awshelper.EcsService
+	cluster    string
+	task       string
+	container  string
+	target     string
+	identity   string
+	startTime  time.Time
+	forwards   []*exec.Cmd
+}
+
+func newEscape(escapeChar string, handler *escapeHandler) (*session.Escape, error) {
+	if escapeChar == "none" {
+		return nil, nil
+	}
+	if len(escapeChar) != 1 {
+		return nil, fmt.Errorf("invalid escape character: %s", escapeChar)
+	}
+	return &session.Escape{
+		Char:    escapeChar[0],
+		Help:    utils.Message("INF017", escapeChar),
+		Info:    handler.info,
+		Command: handler.command,
+	}, nil
+}
+
+func (handler *escapeHandler) info() string {
+	return utils.Message("INF018", handler.target, handler.identity, time.Since(handler.startTime).Round(time.Second))
+}
+
+func (handler *escapeHandler) command(line string) string {
+	args := strings.Fields(line)
+	if len(args) != 2 || args[0] != "-L" {
+		return utils.Message("INF019")
+	}
+	localPort, remotePort, err := parsePorts(args[1])
+	if err != nil {
+		return utils.Message("INF019")
+	}
+	if err := handler.startPortForwarding(localPort, remotePort); err != nil {
+		return utils.Message("ERR013", err)
+	}
+	return utils.Message("INF020", localPort, handler.container, remotePort)
+}
+
+// [ローカルポート:]リモートポート の形式で、ローカルポートを省略した場合はリモートと同じ番号とする
+func parsePorts(arg string) (string, string, error) {
+	localPort, remotePort, found := strings.Cut(arg, ":")
+	if !found {
+		localPort, remotePort = arg, arg
+	}
+	for _, port := range []string{localPort, remotePort} {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return "", "", fmt.Errorf("invalid port: %s", port)
+		}
+	}
+	return localPort, remotePort, nil
+}
+
+func (handler *escapeHandler) startPortForwarding(localPort string, remotePort string) error {
+	runtimeId, err := handler.ecsService.GetContainerRuntimeId(handler.cluster, handler.task, handler.container)
+	if err != nil {
+		return err
+	}
+	ssmService := awshelper.SsmService{}
+	ssmService.SetSsmClient(handler.awsConfig)
+	resp, params, err := ssmService.StartPortForwarding(awshelper.EcsTarget(handler.cluster, handler.task, runtimeId), remotePort, localPort)
+	if err != nil {
+		return err
+	}
+	args, err := portForwardingArgs(resp, params, handler.awsConfig.Region, handler.profile)
+	if err != nil {
+		return err
+	}
+	forward := exec.Command(handler.plugin, args...)
+	if err := forward.Start(); err != nil {
+		return err
+	}
+	handler.forwards = append(handler.forwards, forward)
+	return nil
+}
+
+func (handler *escapeHandler) close() {
+	for _, forward := range handler.forwards {
+		forward.Process.Kill()
+		forward.Wait()
+	}
+}
+
+func portForwardingArgs(resp *ssm.StartSessionOutput, params awshelper.PortForwardingParams, region string, profile string) ([]string, error) {
+	ses, err := json.Marshal(map[string]string{
+		"SessionId":  aws.ToString(resp.SessionId),
+		"TokenValue": aws.ToString(resp.TokenValue),
+		"StreamUrl":  aws.ToString(resp.StreamUrl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	input, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("https://ssm.%s.amazonaws.com", region)
+	return []string{string(ses), region, "StartSession", profile, string(input), endpoint}, nil
+}
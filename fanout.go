@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/audit"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/output"
+	"github.com/gajirou/fexec/pkg/probe"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+const defaultParallel = 5
+
+type taskResult struct {
+	Task       string `json:"task" yaml:"task"`
+	ExitStatus int    `json:"exitStatus" yaml:"exitStatus"`
+	Duration   string `json:"duration" yaml:"duration"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// サービスの稼働中の全てのタスクで同じコマンドを並列に実行し、出力の各行の先頭にタスク ID を付ける
+func executeAll(opts *options, args []string) error {
+	// -command はスクリプトとしてそのまま実行し、-- の後の引数は一つずつ引用符で囲む
+	command := opts.command
+	if len(args) > 0 {
+		command = awshelper.JoinArgs(args)
+	}
+	if command == probe.Auto {
+		utils.PrintMessage("ERR038")
+		return errors.New("a command is required for -all-tasks")
+	}
+	printer := opts.printer()
+	if err := printer.Validate(); err != nil {
+		utils.PrintMessage("ERR032", err)
+		return err
+	}
+
+	plugin, err := findPlugin()
+	if err != nil {
+		return err
+	}
+	awsConfig, err := loadAWSConfig(opts)
+	if err != nil {
+		return err
+	}
+	ecsService := newEcsService(awsConfig, opts)
+	// コンテナは先頭のタスク（-pick の指定があればそのタスク）で選択し、全てのタスクで同じコンテナを使う
+	if opts.pick == "" {
+		opts.pick = awshelper.PickFirst
+	}
+	tgt, err := selectSimple(ecsService, opts)
+	if err != nil {
+		return err
+	}
+	if err := requireReason(opts, tgt.Cluster, tgt.Service); err != nil {
+		return err
+	}
+	tasks, err := runningTasks(ecsService, tgt)
+	if err != nil {
+		utils.PrintMessage("ERR005")
+		return err
+	}
+	if len(tasks) <= 0 {
+		utils.PrintMessage("INF005")
+		return errNotFound
+	}
+	utils.PrintMessage("INF032", len(tasks), opts.parallel)
+
+	// タスク定義ごとに一度だけプラットフォームを判定する
+	platforms := map[string]awshelper.TaskPlatform{}
+	for _, task := range tasks {
+		if _, ok := platforms[task.TaskDefinitionArn]; !ok {
+			platforms[task.TaskDefinitionArn] = ecsService.TaskInfoPlatform(task)
+		}
+	}
+	auditor := newAuditor(awsConfig, opts)
+
+	results := make([]taskResult, len(tasks))
+	limit := make(chan struct{}, opts.parallel)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			taskTarget := tgt
+			taskTarget.Task = task.Id
+			remote := newPlatformCommand(plugin, awsConfig, ecsService, taskTarget, platforms[task.TaskDefinitionArn])
+			script := command
+			if len(args) > 0 {
+				script = remote.joinArgs(args)
+			}
+			script = remote.isolate(script)
+			if opts.reason != "" {
+				script = remote.withEnv(script, map[string]string{"FEXEC_REASON": opts.reason})
+			}
+			tagged := output.NewTagWriter(os.Stdout, &mu, task.Id)
+			startTime := time.Now()
+			runErr := remote.run(script, strings.NewReader(""), tagged)
+			tagged.Flush()
+			endTime := time.Now()
+
+			result := taskResult{
+				Task:       task.Id,
+				ExitStatus: sessionExitStatus(runErr),
+				Duration:   endTime.Sub(startTime).Round(time.Millisecond).String(),
+			}
+			var remoteErr *remoteExitError
+			if runErr != nil && !errors.As(runErr, &remoteErr) {
+				result.Error = runErr.Error()
+			}
+			results[i] = result
+
+			auditor.record(audit.Entry{
+				Cluster:    tgt.Cluster,
+				Service:    tgt.Service,
+				TaskArn:    task.Arn,
+				Container:  tgt.Container,
+				Command:    command,
+				StartTime:  startTime,
+				EndTime:    endTime,
+				ExitStatus: result.ExitStatus,
+			}, remote.sessionId, "")
+		}()
+	}
+	wg.Wait()
+	auditor.wait()
+
+	failed := 0
+	header := []string{"TASK", "EXIT", "DURATION", "ERROR"}
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		if r.ExitStatus != 0 {
+			failed++
+		}
+		rows = append(rows, []string{r.Task, strconv.Itoa(r.ExitStatus), r.Duration, r.Error})
+	}
+	// 各タスクの出力と混ざらないよう、結果の一覧は標準エラー出力に表示する
+	if err := printer.Print(os.Stderr, results, header, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		utils.PrintMessage("ERR039", failed, len(results))
+		return &remoteExitError{status: 1}
+	}
+	return nil
+}
+
+// ListTasks には起動中や停止中のタスクも含まれるため、稼働中のタスクに絞り込む
+func runningTasks(ecsService *awshelper.EcsService, tgt target) ([]awshelper.TaskInfo, error) {
+	tasks, err := ecsService.GetTasks(tgt.Cluster, tgt.Service)
+	if err != nil || len(tasks) <= 0 {
+		return nil, err
+	}
+	infos, err := ecsService.DescribeTaskInfos(tgt.Cluster, tasks)
+	if err != nil {
+		return nil, err
+	}
+	var running []awshelper.TaskInfo
+	for _, t := range infos {
+		if t.LastStatus == taskRunning {
+			running = append(running, t)
+		}
+	}
+	return running, nil
+}
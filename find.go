@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/output"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+// ログなどに出力されたプライベート IP・ENI ID・タスク ID の一部からタスクを探し、選択したタスクに接続する
+func runFind(cfg config.Config, args []string) error {
+	flags, opts := newFindFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	query := flags.Arg(0)
+	if flags.NArg() != 1 || query == "" {
+		flags.Usage()
+		return fmt.Errorf("a private IP, ENI ID or task ID is required for find")
+	}
+	printer := opts.printer()
+	if err := printer.Validate(); err != nil {
+		utils.PrintMessage("ERR032", err)
+		return err
+	}
+
+	awsConfig, err := loadAWSConfig(opts)
+	if err != nil {
+		return err
+	}
+	ecsService := newEcsService(awsConfig, opts)
+	var clusters []string
+	if opts.target.Cluster != "" {
+		clusters = []string{opts.target.Cluster}
+	}
+	found, err := ecsService.FindTasks(clusters, query)
+	if err != nil {
+		utils.PrintMessage("ERR040")
+		return err
+	}
+	if len(found) <= 0 {
+		utils.PrintMessage("INF034", query)
+		return errNotFound
+	}
+
+	header := []string{"CLUSTER", "SERVICE", "TASK", "STATUS", "AZ", "IP", "ENI", "STARTED"}
+	rows := make([][]string, 0, len(found))
+	for _, t := range found {
+		rows = append(rows, []string{t.Cluster, t.Service, t.Id, t.LastStatus, t.AvailabilityZone, t.PrivateIp, t.NetworkInterfaceId, formatTime(t.StartedAt)})
+	}
+	if err := printer.Print(os.Stdout, found, header, rows); err != nil {
+		return err
+	}
+	// 一覧をパイプで渡す場合や JSON・YAML で出力する場合は接続しない
+	if printer.Format != output.Table || !canUseTui() {
+		return nil
+	}
+
+	options := make([]string, 0, len(found)+1)
+	for _, t := range found {
+		options = append(options, strings.Join([]string{t.Cluster, t.Service, t.Id}, "/"))
+	}
+	selected, err := utils.ScreenDraw(append(options, utils.Label("find.skip")), "find")
+	if err != nil {
+		utils.PrintMessage("ERR999")
+		return err
+	}
+	for i, option := range options {
+		if option == selected {
+			opts.target = target{Cluster: found[i].Cluster, Service: found[i].Service, Task: found[i].Id, Container: opts.target.Container}
+			return execute(opts)
+		}
+	}
+	return nil
+}
+
+func newFindFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("find")
+	opts := newOptions(flags, cfg)
+	return flags, opts
+}
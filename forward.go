@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/gajirou/fexec/pkg/audit"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+func runPortForward(cfg config.Config, args []string) error {
+	flags, opts := newPortForwardFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("port is required")
+	}
+	localPort, remotePort, err := parsePorts(flags.Arg(0))
+	if err != nil {
+		flags.Usage()
+		return err
+	}
+
+	plugin, err := findPlugin()
+	if err != nil {
+		return err
+	}
+	awsConfig, ecsService, tgt, err := chooseTarget(opts)
+	if err != nil {
+		return err
+	}
+	if err := requireReason(opts, tgt.Cluster, tgt.Service); err != nil {
+		return err
+	}
+	runtimeId, err := ecsService.GetContainerRuntimeId(tgt.Cluster, tgt.Task, tgt.Container)
+	if err != nil {
+		return err
+	}
+	taskArn := tgt.Task
+	if tasks, err := ecsService.DescribeTaskInfos(tgt.Cluster, []string{tgt.Task}); err == nil && len(tasks) > 0 {
+		taskArn = tasks[0].Arn
+	}
+	auditor := newAuditor(awsConfig, opts)
+	ssmService := awshelper.SsmService{}
+	ssmService.SetSsmClient(awsConfig)
+	resp, params, err := ssmService.StartPortForwarding(awshelper.EcsTarget(tgt.Cluster, tgt.Task, runtimeId), remotePort, localPort)
+	if err != nil {
+		return err
+	}
+	pluginArgs, err := portForwardingArgs(resp, params, awsConfig.Region, awshelper.ResolveProfile(opts.profile))
+	if err != nil {
+		utils.PrintMessage("ERR999")
+		return err
+	}
+
+	// Ctrl-C はプラグインにも届くため、fexec 側では終了の合図としてのみ扱う
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	utils.PrintMessage("INF020", localPort, tgt.Container, remotePort)
+	forward := exec.Command(plugin, pluginArgs...)
+	forward.Stdout = os.Stdout
+	forward.Stderr = os.Stderr
+	startTime := time.Now()
+	err = forward.Run()
+	select {
+	case <-sigs:
+		err = nil
+	default:
+	}
+	auditor.record(audit.Entry{
+		Cluster:    tgt.Cluster,
+		Service:    tgt.Service,
+		TaskArn:    taskArn,
+		Container:  tgt.Container,
+		Command:    fmt.Sprintf("port-forward %s:%s", localPort, remotePort),
+		StartTime:  startTime,
+		EndTime:    time.Now(),
+		ExitStatus: session.ExitStatus(err),
+	}, aws.ToString(resp.SessionId), "")
+	auditor.wait()
+	return err
+}
+
+func newPortForwardFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("port-forward")
+	opts := globalOptions(flags, cfg)
+	opts.targetFlags(flags)
+	opts.selectFlags(flags)
+	opts.reasonFlag(flags)
+	return flags, opts
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/history"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+func runHistory(cfg config.Config, args []string) error {
+	flags, opts := newHistoryFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	historyStore := history.NewStore(findHistoryFile())
+	entries, err := historyStore.List()
+	if err != nil {
+		utils.PrintMessage("ERR015")
+		return err
+	}
+	if len(entries) <= 0 {
+		utils.PrintMessage("INF022")
+		return errNotFound
+	}
+
+	labels := make([]string, 0, len(entries))
+	byLabel := map[string]history.Entry{}
+	for _, e := range entries {
+		label := fmt.Sprintf("%s  %s/%s  %s/%s/%s", e.Time.Local().Format(time.DateTime), e.Profile, e.Region, e.Cluster, e.Service, e.Container)
+		labels = append(labels, label)
+		byLabel[label] = e
+	}
+	label, err := utils.ScreenDraw(labels, "history")
+	if err != nil {
+		utils.PrintMessage("ERR999")
+		return err
+	}
+	if label == "" {
+		utils.PrintMessage("INF021")
+		return errCancelled
+	}
+	opts.useHistory(byLabel[label])
+	return execute(opts)
+}
+
+func newHistoryFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("history")
+	opts := newOptions(flags, cfg)
+	return flags, opts
+}
+
+func (opts *options) useHistory(entry history.Entry) {
+	if entry.Profile != "" {
+		opts.profile = entry.Profile
+	}
+	opts.region = entry.Region
+	opts.pick = awshelper.DefaultPick(opts.pick)
+	opts.target = target{
+		Cluster:   entry.Cluster,
+		Service:   entry.Service,
+		Task:      entry.Task,
+		Container: entry.Container,
+	}
+}
+
+func findHistoryFile() string {
+	return filepath.Join(utils.StateDir(), "history.json")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+const (
+	defaultLogLines = 50
+	followInterval  = 2 * time.Second
+)
+
+func runLogs(cfg config.Config, args []string) error {
+	flags, opts, lines, follow := newLogsFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 || *lines < 1 || *lines > 10000 {
+		flags.Usage()
+		return fmt.Errorf("invalid arguments for logs")
+	}
+
+	awsConfig, ecsService, tgt, err := chooseTarget(opts)
+	if err != nil {
+		return err
+	}
+	stream, err := ecsService.GetLogStream(tgt.Cluster, tgt.Task, tgt.Container)
+	if err != nil {
+		utils.PrintMessage("ERR034", err)
+		return err
+	}
+	logsService := awshelper.LogsService{}
+	logsService.SetLogsClient(awsConfig)
+	events, token, err := logsService.GetLogEventsPage(stream, int32(*lines), "")
+	if err != nil {
+		return err
+	}
+	printLogEvents(events)
+	if !*follow {
+		return nil
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-sigs:
+			return nil
+		case <-time.After(followInterval):
+		}
+		events, token, err = logsService.GetLogEventsPage(stream, 0, token)
+		if err != nil {
+			return err
+		}
+		printLogEvents(events)
+	}
+}
+
+func newLogsFlags(cfg config.Config) (*flag.FlagSet, *options, *int, *bool) {
+	flags := newFlagSet("logs")
+	opts := globalOptions(flags, cfg)
+	opts.targetFlags(flags)
+	opts.selectFlags(flags)
+	lines := flags.Int("n", defaultLogLines, "表示する行数（1〜10000）")
+	follow := flags.Bool("f", false, "新しいログを待ち続けて表示")
+	return flags, opts, lines, follow
+}
+
+func printLogEvents(events []awshelper.LogEvent) {
+	for _, e := range events {
+		fmt.Println(formatLogEvent(e))
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+var resourceKinds = map[string]string{
+	"cluster":    "clusters",
+	"clusters":   "clusters",
+	"service":    "services",
+	"services":   "services",
+	"task":       "tasks",
+	"tasks":      "tasks",
+	"container":  "containers",
+	"containers": "containers",
+}
+
+func runLs(cfg config.Config, args []string) error {
+	flags, opts, tmpl := newLsFlags(cfg)
+	name, err := parseWithName(flags, args, "ERR031")
+	if err != nil {
+		return err
+	}
+	printer := opts.printer()
+	printer.Template = *tmpl
+	kind, ok := resourceKinds[name]
+	if !ok {
+		utils.PrintMessage("ERR031")
+		return fmt.Errorf("unknown resource: %s", name)
+	}
+	if err := printer.Validate(); err != nil {
+		utils.PrintMessage("ERR032", err)
+		return err
+	}
+	tgt := opts.target
+	if (kind != "clusters" && tgt.Cluster == "") || (kind == "containers" && tgt.Task == "") {
+		utils.PrintMessage("ERR031")
+		return fmt.Errorf("missing -cluster or -task for %s", kind)
+	}
+
+	awsConfig, err := loadAWSConfig(opts)
+	if err != nil {
+		return err
+	}
+	ecsService := newEcsService(awsConfig, opts)
+	switch kind {
+	case "clusters":
+		clusters, err := ecsService.ListClusterInfos()
+		if err != nil {
+			utils.PrintMessage("ERR003")
+			return err
+		}
+		header := []string{"NAME", "STATUS", "SERVICES", "RUNNING", "PENDING"}
+		rows := make([][]string, 0, len(clusters))
+		for _, c := range clusters {
+			rows = append(rows, []string{c.Name, c.Status, fmt.Sprint(c.ActiveServices), fmt.Sprint(c.RunningTasks), fmt.Sprint(c.PendingTasks)})
+		}
+		return printer.Print(os.Stdout, clusters, header, rows)
+	case "services":
+		services, err := ecsService.ListServiceInfos(tgt.Cluster)
+		if err != nil {
+			utils.PrintMessage("ERR004")
+			return err
+		}
+		header := []string{"NAME", "STATUS", "DESIRED", "RUNNING", "PENDING", "LAUNCH", "EXEC", "TASK DEFINITION"}
+		rows := make([][]string, 0, len(services))
+		for _, s := range services {
+			rows = append(rows, []string{s.Name, s.Status, fmt.Sprint(s.DesiredCount), fmt.Sprint(s.RunningCount), fmt.Sprint(s.PendingCount), s.LaunchType, fmt.Sprint(s.EnableExecuteCommand), path.Base(s.TaskDefinition)})
+		}
+		return printer.Print(os.Stdout, services, header, rows)
+	case "tasks":
+		tasks, err := ecsService.ListTaskInfos(tgt.Cluster, tgt.Service)
+		if err != nil {
+			utils.PrintMessage("ERR005")
+			return err
+		}
+		header := []string{"ID", "STATUS", "DESIRED", "HEALTH", "LAUNCH", "AZ", "IP", "STARTED", "EXEC", "TASK DEFINITION"}
+		rows := make([][]string, 0, len(tasks))
+		for _, t := range tasks {
+			rows = append(rows, []string{t.Id, t.LastStatus, t.DesiredStatus, t.HealthStatus, t.LaunchType, t.AvailabilityZone, t.PrivateIp, formatTime(t.StartedAt), fmt.Sprint(t.EnableExecuteCommand), path.Base(t.TaskDefinitionArn)})
+		}
+		return printer.Print(os.Stdout, tasks, header, rows)
+	default:
+		containers, err := ecsService.ListContainerInfos(tgt.Cluster, tgt.Task)
+		if err != nil {
+			utils.PrintMessage("ERR006")
+			return err
+		}
+		header := []string{"NAME", "STATUS", "HEALTH", "ESSENTIAL", "EXEC AGENT", "RUNTIME ID", "IMAGE"}
+		rows := make([][]string, 0, len(containers))
+		for _, c := range containers {
+			rows = append(rows, []string{c.Name, c.LastStatus, c.HealthStatus, fmt.Sprint(c.Essential), c.ExecAgent, c.RuntimeId, c.Image})
+		}
+		return printer.Print(os.Stdout, containers, header, rows)
+	}
+}
+
+func newLsFlags(cfg config.Config) (*flag.FlagSet, *options, *string) {
+	flags := newFlagSet("ls")
+	opts := globalOptions(flags, cfg)
+	opts.targetFlags(flags)
+	tmpl := flags.String("template", "", "Go テンプレート（要素ごとに出力）")
+	return flags, opts, tmpl
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
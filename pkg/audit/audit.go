@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	maxLineSize = 1024 * 1024
+	DateFormat  = "2006-01-02"
+)
+
+var (
+	ErrInvalidDate = errors.New("date must be YYYY-MM-DD")
+)
+
+type Entry struct {
+	Identity   string    `json:"identity"`
+	Profile    string    `json:"profile"`
+	Region     string    `json:"region"`
+	Cluster    string    `json:"cluster"`
+	Service    string    `json:"service"`
+	TaskArn    string    `json:"task_arn"`
+	Container  string    `json:"container"`
+	Command    string    `json:"command"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	ExitStatus int       `json:"exit_status"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type Filter struct {
+	Date   string
+	Target string
+}
+
+type Log struct {
+	Path string
+}
+
+func NewLog(path string) Log {
+	return Log{Path: path}
+}
+
+func (log *Log) Append(entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(log.Path), 0o700); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(log.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+func (log *Log) Query(filter Filter) (entries []Entry, err error) {
+	if filter.Date != "" {
+		if _, err := time.Parse(DateFormat, filter.Date); err != nil {
+			return nil, ErrInvalidDate
+		}
+	}
+	file, err := os.Open(log.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func (filter Filter) match(entry Entry) bool {
+	if filter.Date != "" && entry.StartTime.Local().Format(DateFormat) != filter.Date {
+		return false
+	}
+	if filter.Target != "" {
+		targets := []string{entry.Cluster, entry.Service, entry.TaskArn, entry.Container}
+		for _, t := range targets {
+			if strings.Contains(t, filter.Target) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package audit_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/audit"
+)
+
+func TestQuery(t *testing.T) {
+	auditLog := audit.NewLog(filepath.Join(t.TempDir(), "audit", "audit.log"))
+	entries := []audit.Entry{
+		{
+			Cluster:   "prod",
+			Service:   "api",
+			TaskArn:   "arn:aws:ecs:ap-northeast-1:111111111111:task/prod/task1",
+			Container: "app",
+			StartTime: time.Date(2024, 4, 1, 12, 0, 0, 0, time.Local),
+		},
+		{
+			Cluster:   "stg",
+			Service:   "worker",
+			TaskArn:   "arn:aws:ecs:ap-northeast-1:111111111111:task/stg/task2",
+			Container: "app",
+			StartTime: time.Date(2024, 4, 2, 12, 0, 0, 0, time.Local),
+		},
+	}
+	for _, e := range entries {
+		if err := auditLog.Append(e); err != nil {
+			t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter audit.Filter
+		want   int
+		err    error
+	}{
+		{
+			name:   "正常パターン:条件なし",
+			filter: audit.Filter{},
+			want:   2,
+		},
+		{
+			name:   "正常パターン:日付指定",
+			filter: audit.Filter{Date: "2024-04-01"},
+			want:   1,
+		},
+		{
+			name:   "正常パターン:対象指定",
+			filter: audit.Filter{Target: "worker"},
+			want:   1,
+		},
+		{
+			name:   "正常パターン:日付と対象の不一致",
+			filter: audit.Filter{Date: "2024-04-01", Target: "stg"},
+			want:   0,
+		},
+		{
+			name:   "異常パターン:日付の形式が不正",
+			filter: audit.Filter{Date: "2024/04/01"},
+			want:   0,
+			err:    audit.ErrInvalidDate,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := auditLog.Query(c.filter)
+			if !errors.Is(err, c.err) {
+				t.Errorf("関数の戻り値のエラーが期待値と異なります：%v", err)
+			}
+			if len(got) != c.want {
+				t.Errorf("取得件数が期待値と異なります：%d", len(got))
+			}
+		})
+	}
+}
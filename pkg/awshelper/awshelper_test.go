@@ -1,14 +1,27 @@
 package awshelper_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 	"github.com/gajirou/fexec/pkg/awshelper"
 )
 
@@ -22,27 +35,69 @@ func (m mockConfigService) LoadDefaultConfig(ctx context.Context, optFns ...func
 }
 
 type mockEcsService struct {
-	listClusterOutput    ecs.ListClustersOutput
-	listServicesOutput   ecs.ListServicesOutput
-	listTaskOutput       ecs.ListTasksOutput
-	describeTasksOutput  ecs.DescribeTasksOutput
-	executeCommandOutput ecs.ExecuteCommandOutput
-	err                  error
+	listClusterOutput            ecs.ListClustersOutput
+	listClusterPages             []ecs.ListClustersOutput
+	listServicesOutput           ecs.ListServicesOutput
+	listServicePages             []ecs.ListServicesOutput
+	listTaskOutput               ecs.ListTasksOutput
+	listTaskPages                []ecs.ListTasksOutput
+	described                    *[][]string
+	describeClustersOutput       ecs.DescribeClustersOutput
+	describeServicesOutput       ecs.DescribeServicesOutput
+	describeTasksOutput          ecs.DescribeTasksOutput
+	describeTaskDefinitionOutput ecs.DescribeTaskDefinitionOutput
+	executeCommandOutput         ecs.ExecuteCommandOutput
+	err                          error
 }
 
+// described を指定した場合は、Describe* に渡した ARN を呼び出しごとに記録する
+func (m mockEcsService) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	m.describe(params.Clusters)
+	return &m.describeClustersOutput, m.err
+}
+
+func (m mockEcsService) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	m.describe(params.Services)
+	return &m.describeServicesOutput, m.err
+}
+
+func (m mockEcsService) DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error) {
+	return &m.describeTaskDefinitionOutput, m.err
+}
+
+func (m mockEcsService) describe(arns []string) {
+	if m.described != nil {
+		*m.described = append(*m.described, arns)
+	}
+}
+
+// list*Pages を指定した場合は、NextToken をページ番号として扱う
 func (m mockEcsService) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	if len(m.listClusterPages) > 0 {
+		page, _ := strconv.Atoi(aws.ToString(params.NextToken))
+		return &m.listClusterPages[page], m.err
+	}
 	return &m.listClusterOutput, m.err
 }
 
 func (m mockEcsService) ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
+	if len(m.listServicePages) > 0 {
+		page, _ := strconv.Atoi(aws.ToString(params.NextToken))
+		return &m.listServicePages[page], m.err
+	}
 	return &m.listServicesOutput, m.err
 }
 
 func (m mockEcsService) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	if len(m.listTaskPages) > 0 {
+		page, _ := strconv.Atoi(aws.ToString(params.NextToken))
+		return &m.listTaskPages[page], m.err
+	}
 	return &m.listTaskOutput, m.err
 }
 
 func (m mockEcsService) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	m.describe(params.Tasks)
 	return &m.describeTasksOutput, m.err
 }
 
@@ -50,6 +105,25 @@ func (m mockEcsService) ExecuteCommand(ctx context.Context, params *ecs.ExecuteC
 	return &m.executeCommandOutput, m.err
 }
 
+type mockStsService struct {
+	getCallerIdentityOutput sts.GetCallerIdentityOutput
+	err                     error
+}
+
+func (m mockStsService) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return &m.getCallerIdentityOutput, m.err
+}
+
+type mockS3Service struct {
+	params *s3.PutObjectInput
+	err    error
+}
+
+func (m *mockS3Service) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.params = params
+	return &s3.PutObjectOutput{}, m.err
+}
+
 func TestFindProfile(t *testing.T) {
 	cases := []struct {
 		name              string
@@ -270,6 +344,22 @@ func TestGetTasks(t *testing.T) {
 	}
 }
 
+func TestGetTasksPages(t *testing.T) {
+	mockEcsService := &mockEcsService{listTaskPages: []ecs.ListTasksOutput{
+		{TaskArns: []string{"arn:aws:ecs:ap-northeast-1:1:task/main/task1"}, NextToken: aws.String("1")},
+		{TaskArns: []string{"arn:aws:ecs:ap-northeast-1:1:task/main/task2"}},
+	}}
+	mockService := awshelper.EcsService{Service: mockEcsService}
+
+	tasks, err := mockService.GetTasks("main", "api")
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if strings.Join(tasks, ",") != "task1,task2" {
+		t.Errorf("全てのページのタスクが取得されていません：%v", tasks)
+	}
+}
+
 func TestGetContainers(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -361,7 +451,372 @@ func TestExecuteContainer(t *testing.T) {
 			mockEcsService := &mockEcsService{executeCommandOutput: c.resp, err: c.mockError}
 			mockService := awshelper.EcsService{Service: mockEcsService}
 
-			_, err := mockService.ExecuteContainer("cluster", "task", "container")
+			_, err := mockService.ExecuteContainer("cluster", "task", "container", awshelper.DefaultCommand)
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+			} else {
+				if err != nil {
+					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+		})
+	}
+}
+
+func TestGetCallerIdentity(t *testing.T) {
+	cases := []struct {
+		name      string
+		resp      sts.GetCallerIdentityOutput
+		mockError error
+	}{
+		{
+			name: "正常パターン",
+			resp: sts.GetCallerIdentityOutput{
+				Account: aws.String("111111111111"),
+				Arn:     aws.String("arn:aws:iam::111111111111:user/user1"),
+				UserId:  aws.String("AIDAXXXXXXXXXXXXXXXXX"),
+			},
+			mockError: nil,
+		},
+		{
+			name:      "異常パターン",
+			resp:      sts.GetCallerIdentityOutput{},
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockStsService := &mockStsService{getCallerIdentityOutput: c.resp, err: c.mockError}
+			mockService := awshelper.StsService{Service: mockStsService}
+
+			identity, err := mockService.GetCallerIdentity()
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+			} else {
+				if err != nil {
+					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+				if identity.Arn != *c.resp.Arn {
+					t.Error("関数の戻り値が期待値と異なります。")
+				}
+			}
+		})
+	}
+}
+
+func TestUpload(t *testing.T) {
+	cases := []struct {
+		name      string
+		kmsKeyId  string
+		wantSse   s3types.ServerSideEncryption
+		mockError error
+	}{
+		{
+			name:      "正常パターン:SSE-S3",
+			kmsKeyId:  "",
+			wantSse:   s3types.ServerSideEncryptionAes256,
+			mockError: nil,
+		},
+		{
+			name:      "正常パターン:SSE-KMS",
+			kmsKeyId:  "alias/fexec",
+			wantSse:   s3types.ServerSideEncryptionAwsKms,
+			mockError: nil,
+		},
+		{
+			name:      "異常パターン",
+			kmsKeyId:  "",
+			wantSse:   s3types.ServerSideEncryptionAes256,
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockS3Service := &mockS3Service{err: c.mockError}
+			mockService := awshelper.S3Service{Service: mockS3Service, Bucket: "bucket", Prefix: "fexec", KmsKeyId: c.kmsKeyId}
+
+			err := mockService.Upload("account/cluster/2024-04-01/session.json", strings.NewReader("data"))
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+			} else {
+				if err != nil {
+					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+			if *mockS3Service.params.Key != "fexec/account/cluster/2024-04-01/session.json" {
+				t.Error("オブジェクトキーが期待値と異なります。")
+			}
+			if mockS3Service.params.ServerSideEncryption != c.wantSse {
+				t.Error("暗号化方式が期待値と異なります。")
+			}
+		})
+	}
+}
+
+func TestCommandWithEnv(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "正常パターン:環境変数なし",
+			env:  nil,
+			want: "/bin/sh",
+		},
+		{
+			name: "正常パターン:環境変数あり",
+			env:  map[string]string{"FEXEC_REASON": "OPS-1 it's down"},
+			want: `env FEXEC_REASON='OPS-1 it'\''s down' /bin/sh`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := awshelper.CommandWithEnv(awshelper.DefaultCommand, c.env); got != c.want {
+				t.Errorf("関数の戻り値が期待値と異なります：%s", got)
+			}
+		})
+	}
+}
+
+func TestGetTaskStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		resp      ecs.DescribeTasksOutput
+		want      string
+		mockError error
+	}{
+		{
+			name: "正常パターン:稼働中",
+			resp: ecs.DescribeTasksOutput{
+				Tasks: []types.Task{{LastStatus: aws.String("RUNNING")}},
+			},
+			want:      "RUNNING",
+			mockError: nil,
+		},
+		{
+			name:      "正常パターン:タスクが存在しない",
+			resp:      ecs.DescribeTasksOutput{},
+			want:      "",
+			mockError: nil,
+		},
+		{
+			name:      "異常パターン",
+			resp:      ecs.DescribeTasksOutput{},
+			want:      "",
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockEcsService := &mockEcsService{describeTasksOutput: c.resp, err: c.mockError}
+			mockService := awshelper.EcsService{Service: mockEcsService}
+
+			status, err := mockService.GetTaskStatus("cluster", "task")
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+			} else {
+				if err != nil {
+					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+			if status != c.want {
+				t.Errorf("関数の戻り値が期待値と異なります：%s", status)
+			}
+		})
+	}
+}
+
+func TestListClusterInfos(t *testing.T) {
+	cases := []struct {
+		name      string
+		list      ecs.ListClustersOutput
+		resp      ecs.DescribeClustersOutput
+		want      int
+		mockError error
+	}{
+		{
+			name: "正常パターン",
+			list: ecs.ListClustersOutput{
+				ClusterArns: []string{
+					"arn:aws:ecs:ap-northeast-1:111111111111:cluster/cluster2",
+					"arn:aws:ecs:ap-northeast-1:111111111111:cluster/cluster1",
+				},
+			},
+			resp: ecs.DescribeClustersOutput{
+				Clusters: []types.Cluster{
+					{ClusterName: aws.String("cluster2"), Status: aws.String("ACTIVE"), RunningTasksCount: 2},
+					{ClusterName: aws.String("cluster1"), Status: aws.String("ACTIVE"), RunningTasksCount: 1},
+				},
+			},
+			want:      2,
+			mockError: nil,
+		},
+		{
+			name:      "正常パターン:クラスターが存在しない",
+			list:      ecs.ListClustersOutput{},
+			resp:      ecs.DescribeClustersOutput{},
+			want:      0,
+			mockError: nil,
+		},
+		{
+			name:      "異常パターン",
+			list:      ecs.ListClustersOutput{},
+			resp:      ecs.DescribeClustersOutput{},
+			want:      0,
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockEcsService := &mockEcsService{listClusterOutput: c.list, describeClustersOutput: c.resp, err: c.mockError}
+			mockService := awshelper.EcsService{Service: mockEcsService}
+
+			clusters, err := mockService.ListClusterInfos()
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+			} else {
+				if err != nil {
+					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+			if len(clusters) != c.want {
+				t.Fatalf("取得件数が期待値と異なります：%d", len(clusters))
+			}
+			if c.want > 0 && clusters[0].Name != "cluster1" {
+				t.Error("クラスターがソートされていません。")
+			}
+		})
+	}
+}
+
+func TestListInfosPages(t *testing.T) {
+	arns := func(kind string, from int, to int) (arns []string) {
+		for i := from; i < to; i++ {
+			arns = append(arns, fmt.Sprintf("arn:aws:ecs:ap-northeast-1:1:%s/main/%s%d", kind, kind, i))
+		}
+		return arns
+	}
+	cases := []struct {
+		name   string
+		mock   mockEcsService
+		list   func(ecsService awshelper.EcsService) error
+		chunks []int
+	}{
+		{
+			name: "正常パターン:クラスター",
+			mock: mockEcsService{listClusterPages: []ecs.ListClustersOutput{
+				{ClusterArns: arns("cluster", 0, 60), NextToken: aws.String("1")},
+				{ClusterArns: arns("cluster", 60, 120)},
+			}},
+			list: func(ecsService awshelper.EcsService) error {
+				_, err := ecsService.ListClusterInfos()
+				return err
+			},
+			chunks: []int{100, 20},
+		},
+		{
+			name: "正常パターン:サービス",
+			mock: mockEcsService{listServicePages: []ecs.ListServicesOutput{
+				{ServiceArns: arns("service", 0, 8), NextToken: aws.String("1")},
+				{ServiceArns: arns("service", 8, 12)},
+			}},
+			list: func(ecsService awshelper.EcsService) error {
+				_, err := ecsService.ListServiceInfos("main")
+				return err
+			},
+			chunks: []int{10, 2},
+		},
+		{
+			name: "正常パターン:タスク",
+			mock: mockEcsService{listTaskPages: []ecs.ListTasksOutput{
+				{TaskArns: arns("task", 0, 60), NextToken: aws.String("1")},
+				{TaskArns: arns("task", 60, 120)},
+			}},
+			list: func(ecsService awshelper.EcsService) error {
+				_, err := ecsService.ListTaskInfos("main", "api")
+				return err
+			},
+			chunks: []int{100, 20},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var described [][]string
+			c.mock.described = &described
+			if err := c.list(awshelper.EcsService{Service: c.mock}); err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			var sizes []int
+			for _, d := range described {
+				sizes = append(sizes, len(d))
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(c.chunks) {
+				t.Errorf("全てのページが上限件数ごとに取得されていません：%v", sizes)
+			}
+		})
+	}
+}
+
+func TestListTaskInfos(t *testing.T) {
+	started := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		list      ecs.ListTasksOutput
+		resp      ecs.DescribeTasksOutput
+		want      []string
+		mockError error
+	}{
+		{
+			name: "正常パターン",
+			list: ecs.ListTasksOutput{
+				TaskArns: []string{
+					"arn:aws:ecs:ap-northeast-1:111111111111:task/cluster/task1",
+					"arn:aws:ecs:ap-northeast-1:111111111111:task/cluster/task2",
+				},
+			},
+			resp: ecs.DescribeTasksOutput{
+				Tasks: []types.Task{
+					{
+						TaskArn:   aws.String("arn:aws:ecs:ap-northeast-1:111111111111:task/cluster/task1"),
+						StartedAt: aws.Time(started),
+					},
+					{
+						TaskArn:   aws.String("arn:aws:ecs:ap-northeast-1:111111111111:task/cluster/task2"),
+						StartedAt: aws.Time(started.Add(time.Hour)),
+						Containers: []types.Container{
+							{NetworkInterfaces: []types.NetworkInterface{{PrivateIpv4Address: aws.String("10.0.0.1")}}},
+						},
+					},
+				},
+			},
+			want:      []string{"task2", "task1"},
+			mockError: nil,
+		},
+		{
+			name:      "異常パターン",
+			list:      ecs.ListTasksOutput{},
+			resp:      ecs.DescribeTasksOutput{},
+			want:      nil,
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockEcsService := &mockEcsService{listTaskOutput: c.list, describeTasksOutput: c.resp, err: c.mockError}
+			mockService := awshelper.EcsService{Service: mockEcsService}
+
+			tasks, err := mockService.ListTaskInfos("cluster", "service")
 			if c.mockError != nil {
 				if err == nil {
 					t.Error("関数の戻り値にエラーが含まれていません。")
@@ -371,6 +826,502 @@ func TestExecuteContainer(t *testing.T) {
 					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
 				}
 			}
+			var ids []string
+			for _, task := range tasks {
+				ids = append(ids, task.Id)
+			}
+			if strings.Join(ids, ",") != strings.Join(c.want, ",") {
+				t.Errorf("関数の戻り値が期待値と異なります：%v", ids)
+			}
+			if len(tasks) > 0 && tasks[0].PrivateIp != "10.0.0.1" {
+				t.Error("プライベート IP が取得できていません。")
+			}
+		})
+	}
+}
+
+func TestExecuteContainerError(t *testing.T) {
+	cases := []struct {
+		name      string
+		mockError error
+		kind      error
+	}{
+		{
+			name:      "権限不足",
+			mockError: &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"},
+			kind:      awshelper.ErrAccessDenied,
+		},
+		{
+			name:      "クラスターが存在しない",
+			mockError: &smithy.GenericAPIError{Code: "ClusterNotFoundException", Message: "Cluster not found."},
+			kind:      awshelper.ErrClusterNotFound,
+		},
+		{
+			name:      "execute command が無効",
+			mockError: &smithy.GenericAPIError{Code: "InvalidParameterException", Message: "The execute command failed because execute command was not enabled when the task was run."},
+			kind:      awshelper.ErrExecDisabled,
+		},
+		{
+			name:      "エージェントが未接続",
+			mockError: &smithy.GenericAPIError{Code: "TargetNotConnectedException", Message: "The execute command failed due to an internal error."},
+			kind:      awshelper.ErrTargetNotConnected,
+		},
+		{
+			name:      "認証情報の期限切れ",
+			mockError: &smithy.GenericAPIError{Code: "ExpiredTokenException", Message: "The security token included in the request is expired"},
+			kind:      awshelper.ErrExpiredToken,
+		},
+		{
+			name:      "スロットリング",
+			mockError: &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"},
+			kind:      awshelper.ErrThrottled,
+		},
+		{
+			name:      "その他のパラメータエラー",
+			mockError: &smithy.GenericAPIError{Code: "InvalidParameterException", Message: "Invalid container name."},
+			kind:      nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockEcsService := &mockEcsService{err: c.mockError}
+			mockService := awshelper.EcsService{Service: mockEcsService}
+
+			_, err := mockService.ExecuteContainer("cluster", "task", "container", awshelper.DefaultCommand)
+			var apiErr *awshelper.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatal("関数の戻り値が APIError ではありません。")
+			}
+			if apiErr.Action != "ecs:ExecuteCommand" || apiErr.Kind != c.kind {
+				t.Errorf("エラーの内容が期待値と異なります：%+v", apiErr)
+			}
+			if c.kind != nil && !errors.Is(err, c.kind) {
+				t.Error("エラーの種類を判定できません。")
+			}
+			if !errors.Is(err, c.mockError) {
+				t.Error("元のエラーを参照できません。")
+			}
+		})
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	os.WriteFile(configFile, []byte("[default]\nregion = ap-northeast-1\n[profile dev]\nregion = us-east-1\n[sso-session corp]\n"), 0o600)
+	os.WriteFile(credentialsFile, []byte("[default]\n[prod]\n"), 0o600)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+	got := awshelper.ListProfiles()
+	want := []string{"default", "dev", "prod"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("プロファイルの一覧が期待値と異なります：%v", got)
+	}
+}
+
+func TestAppContainer(t *testing.T) {
+	sidecars := []string{"datadog-agent", "envoy", "aws-otel-*"}
+	cases := []struct {
+		name       string
+		containers []awshelper.ContainerInfo
+		want       string
+		found      bool
+	}{
+		{
+			name:       "正常パターン:コンテナが一つ",
+			containers: []awshelper.ContainerInfo{{Name: "app", Essential: true}},
+			want:       "app",
+			found:      true,
+		},
+		{
+			name: "正常パターン:サイドカーを除くと一つ",
+			containers: []awshelper.ContainerInfo{
+				{Name: "app", Essential: true},
+				{Name: "datadog-agent", Essential: true},
+				{Name: "aws-otel-collector", Essential: true},
+			},
+			want:  "app",
+			found: true,
+		},
+		{
+			name: "正常パターン:必須のコンテナが一つ",
+			containers: []awshelper.ContainerInfo{
+				{Name: "app", Essential: true},
+				{Name: "migrate", Essential: false},
+			},
+			want:  "app",
+			found: true,
+		},
+		{
+			name: "異常パターン:必須のコンテナが複数",
+			containers: []awshelper.ContainerInfo{
+				{Name: "api", Essential: true},
+				{Name: "worker", Essential: true},
+				{Name: "envoy", Essential: true},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, found := awshelper.AppContainer(c.containers, sidecars)
+			if got != c.want || found != c.found {
+				t.Errorf("選択されたコンテナが期待値と異なります：%s %v", got, found)
+			}
+		})
+	}
+}
+
+func TestGetTaskPlatform(t *testing.T) {
+	cases := []struct {
+		name       string
+		tasks      ecs.DescribeTasksOutput
+		definition ecs.DescribeTaskDefinitionOutput
+		want       string
+		windows    bool
+		mockError  error
+	}{
+		{
+			name: "正常パターン:タスクのプラットフォーム",
+			tasks: ecs.DescribeTasksOutput{
+				Tasks: []types.Task{{TaskDefinitionArn: aws.String("api:3"), PlatformFamily: aws.String("WINDOWS_SERVER_2022_CORE")}},
+			},
+			want:    "WINDOWS_SERVER_2022_CORE",
+			windows: true,
+		},
+		{
+			name: "正常パターン:タスク定義のプラットフォーム",
+			tasks: ecs.DescribeTasksOutput{
+				Tasks: []types.Task{{TaskDefinitionArn: aws.String("api:3")}},
+			},
+			definition: ecs.DescribeTaskDefinitionOutput{
+				TaskDefinition: &types.TaskDefinition{
+					RuntimePlatform: &types.RuntimePlatform{OperatingSystemFamily: types.OSFamilyWindowsServer2019Full},
+				},
+			},
+			want:    "WINDOWS_SERVER_2019_FULL",
+			windows: true,
+		},
+		{
+			name: "正常パターン:プラットフォームの指定なし",
+			tasks: ecs.DescribeTasksOutput{
+				Tasks: []types.Task{{TaskDefinitionArn: aws.String("api:3")}},
+			},
+			want: "",
+		},
+		{
+			name:      "異常パターン",
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockEcsService := &mockEcsService{describeTasksOutput: c.tasks, describeTaskDefinitionOutput: c.definition, err: c.mockError}
+			mockService := awshelper.EcsService{Service: mockEcsService}
+
+			platform, err := mockService.GetTaskPlatform("cluster", "task")
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			if platform.Family != c.want || platform.Windows() != c.windows || platform.TaskDefinitionArn != "api:3" {
+				t.Errorf("プラットフォームが期待値と異なります：%+v", platform)
+			}
+		})
+	}
+}
+
+func TestPowerShellCommand(t *testing.T) {
+	got := awshelper.PowerShellCommand("Write-Output 'é'")
+	encoded, found := strings.CutPrefix(got, "powershell.exe -NoLogo -NoProfile -EncodedCommand ")
+	if !found {
+		t.Fatalf("コマンドが期待値と異なります：%s", got)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal("スクリプトが base64 で符号化されていません。")
+	}
+	want := []byte{'W', 0, 'r', 0, 'i', 0, 't', 0, 'e', 0, '-', 0, 'O', 0, 'u', 0, 't', 0, 'p', 0, 'u', 0, 't', 0, ' ', 0, '\'', 0, 0xe9, 0, '\'', 0}
+	if !bytes.Equal(data, want) {
+		t.Errorf("スクリプトが UTF-16LE で符号化されていません：%v", data)
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	cases := []struct {
+		name       string
+		args       []string
+		want       string
+		powerShell string
+		output     string
+	}{
+		{
+			name:       "正常パターン:空白とセミコロンを含む引数",
+			args:       []string{"sh", "-c", "echo a b; echo c"},
+			want:       `'sh' '-c' 'echo a b; echo c'`,
+			powerShell: `& 'sh' '-c' 'echo a b; echo c'`,
+			output:     "a b\nc\n",
+		},
+		{
+			name:       "正常パターン:引用符と変数を含む引数",
+			args:       []string{"echo", "it's", "$HOME"},
+			want:       `'echo' 'it'\''s' '$HOME'`,
+			powerShell: `& 'echo' 'it''s' '$HOME'`,
+			output:     "it's $HOME\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := awshelper.JoinArgs(c.args)
+			if got != c.want {
+				t.Errorf("関数の戻り値が期待値と異なります：%s", got)
+			}
+			if got := awshelper.PowerShellJoinArgs(c.args); got != c.powerShell {
+				t.Errorf("関数の戻り値が期待値と異なります：%s", got)
+			}
+			out, err := exec.Command("/bin/sh", "-c", got).Output()
+			if err != nil {
+				t.Fatal("シェルでの実行に失敗しました。")
+			}
+			if string(out) != c.output {
+				t.Errorf("シェルに渡った引数が期待値と異なります：%q", out)
+			}
+		})
+	}
+}
+
+func TestPowerShellWithEnv(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "正常パターン:環境変数なし",
+			env:  nil,
+			want: "powershell.exe",
+		},
+		{
+			name: "正常パターン:環境変数あり",
+			env:  map[string]string{"FEXEC_REASON": "OPS-1 it's down", "A": "1"},
+			want: `$env:A = '1'; $env:FEXEC_REASON = 'OPS-1 it''s down'; powershell.exe`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := awshelper.PowerShellWithEnv(awshelper.WindowsShell, c.env); got != c.want {
+				t.Errorf("関数の戻り値が期待値と異なります：%s", got)
+			}
+		})
+	}
+}
+
+func TestParsePick(t *testing.T) {
+	cases := []struct {
+		name    string
+		pick    string
+		want    awshelper.TaskPick
+		wantErr bool
+	}{
+		{name: "正常パターン:指定なし", pick: "", want: awshelper.TaskPick{Order: "prompt"}},
+		{name: "正常パターン:選び方のみ", pick: "newest", want: awshelper.TaskPick{Order: "newest"}},
+		{name: "正常パターン:条件のみ", pick: "healthy,revision=latest", want: awshelper.TaskPick{Order: "random", Healthy: true, Revision: "latest"}},
+		{name: "正常パターン:条件と選び方", pick: "az=ap-northeast-1a, oldest", want: awshelper.TaskPick{Order: "oldest", Zone: "ap-northeast-1a"}},
+		{name: "正常パターン:リビジョンとアドレス", pick: "revision=12,ip=10.0.12.34", want: awshelper.TaskPick{Order: "random", Revision: "12", Ip: "10.0.12.34"}},
+		{name: "異常パターン:未対応の方法", pick: "last", wantErr: true},
+		{name: "異常パターン:選び方の重複", pick: "first,random", wantErr: true},
+		{name: "異常パターン:値のない条件", pick: "az=", wantErr: true},
+		{name: "異常パターン:不正なリビジョン", pick: "revision=new", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := awshelper.ParsePick(c.pick)
+			if c.wantErr {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			if got != c.want {
+				t.Errorf("選択方法が期待値と異なります：%+v", got)
+			}
+		})
+	}
+}
+
+func TestDefaultPick(t *testing.T) {
+	cases := []struct {
+		name  string
+		pick  string
+		order string
+	}{
+		{name: "正常パターン:選択方法の指定なし", pick: "", order: awshelper.PickFirst},
+		{name: "正常パターン:選択方法の指定あり", pick: "newest", order: awshelper.PickNewest},
+		{name: "正常パターン:選択を指定", pick: "prompt", order: awshelper.PickPrompt},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pick, err := awshelper.ParsePick(awshelper.DefaultPick(c.pick))
+			if err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			if pick.Order != c.order {
+				t.Errorf("選び方が期待値と異なります：%s", pick.Order)
+			}
 		})
 	}
 }
+
+func TestTaskPickFilter(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := []awshelper.TaskInfo{
+		{Id: "a", HealthStatus: "HEALTHY", AvailabilityZone: "ap-northeast-1a", PrivateIp: "10.0.1.1", TaskDefinitionArn: "arn:aws:ecs:ap-northeast-1:1:task-definition/api:9", StartedAt: base},
+		{Id: "b", HealthStatus: "UNHEALTHY", AvailabilityZone: "ap-northeast-1c", PrivateIp: "10.0.1.2", TaskDefinitionArn: "arn:aws:ecs:ap-northeast-1:1:task-definition/api:10", StartedAt: base.Add(time.Hour)},
+		{Id: "c", HealthStatus: "HEALTHY", AvailabilityZone: "ap-northeast-1c", PrivateIp: "10.0.1.3", TaskDefinitionArn: "arn:aws:ecs:ap-northeast-1:1:task-definition/api:10", StartedAt: base.Add(-time.Hour)},
+	}
+	cases := []struct {
+		name string
+		pick string
+		want []string
+	}{
+		{name: "正常パターン:新しい順", pick: "newest", want: []string{"b", "a", "c"}},
+		{name: "正常パターン:古い順", pick: "oldest", want: []string{"c", "a", "b"}},
+		{name: "正常パターン:正常なタスク", pick: "healthy", want: []string{"a", "c"}},
+		{name: "正常パターン:ゾーン", pick: "az=ap-northeast-1c,newest", want: []string{"b", "c"}},
+		{name: "正常パターン:最新のリビジョンで正常なタスク", pick: "healthy,revision=latest", want: []string{"c"}},
+		{name: "正常パターン:リビジョンの指定", pick: "revision=9", want: []string{"a"}},
+		{name: "正常パターン:アドレス", pick: "ip=10.0.1.2", want: []string{"b"}},
+		{name: "異常パターン:一致なし", pick: "ip=10.0.9.9", want: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pick, err := awshelper.ParsePick(c.pick)
+			if err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			var got []string
+			for _, task := range pick.Filter(tasks) {
+				got = append(got, task.Id)
+			}
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("絞り込んだタスクが期待値と異なります：%v", got)
+			}
+		})
+	}
+}
+
+func TestPrimaryRevision(t *testing.T) {
+	cases := []struct {
+		name      string
+		resp      ecs.DescribeServicesOutput
+		mockError error
+		want      string
+	}{
+		{
+			name: "正常パターン:ロールバック中",
+			resp: ecs.DescribeServicesOutput{Services: []types.Service{{Deployments: []types.Deployment{
+				{Status: aws.String("ACTIVE"), TaskDefinition: aws.String("arn:aws:ecs:ap-northeast-1:1:task-definition/api:10")},
+				{Status: aws.String("PRIMARY"), TaskDefinition: aws.String("arn:aws:ecs:ap-northeast-1:1:task-definition/api:9")},
+			}}}},
+			want: "9",
+		},
+		{
+			name: "正常パターン:デプロイなし",
+			resp: ecs.DescribeServicesOutput{Services: []types.Service{{}}},
+			want: "",
+		},
+		{
+			name:      "異常パターン:通常エラー",
+			mockError: errors.New("error"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockService := awshelper.EcsService{Service: &mockEcsService{describeServicesOutput: c.resp, err: c.mockError}}
+			got, err := mockService.PrimaryRevision("main", "api")
+			if c.mockError != nil {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			if got != c.want {
+				t.Errorf("リビジョンが期待値と異なります：%s", got)
+			}
+		})
+	}
+}
+
+func TestMatchTask(t *testing.T) {
+	task := awshelper.TaskInfo{Id: "3f2a9c0d1e", PrivateIp: "10.0.12.34", NetworkInterfaceId: "eni-0123456789abcdef0"}
+	cases := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{name: "正常パターン:プライベート IP", query: "10.0.12.34", want: true},
+		{name: "正常パターン:ENI ID", query: "eni-0123456789abcdef0", want: true},
+		{name: "正常パターン:タスク ID の前方一致", query: "3F2A", want: true},
+		{name: "異常パターン:IP の部分一致", query: "10.0.12.3", want: false},
+		{name: "異常パターン:異なる ENI ID", query: "eni-0123", want: false},
+		{name: "異常パターン:タスク ID の途中", query: "9c0d", want: false},
+		{name: "異常パターン:空文字", query: "", want: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := awshelper.MatchTask(task, c.query); got != c.want {
+				t.Errorf("判定結果が期待値と異なります：%v", got)
+			}
+		})
+	}
+}
+
+func TestFindTasks(t *testing.T) {
+	eni := types.Attachment{
+		Type: aws.String("ElasticNetworkInterface"),
+		Details: []types.KeyValuePair{
+			{Name: aws.String("networkInterfaceId"), Value: aws.String("eni-0123456789abcdef0")},
+			{Name: aws.String("privateIPv4Address"), Value: aws.String("10.0.12.34")},
+		},
+	}
+	mockEcsService := &mockEcsService{
+		listClusterOutput: ecs.ListClustersOutput{ClusterArns: []string{"arn:aws:ecs:ap-northeast-1:1:cluster/main"}},
+		listTaskOutput:    ecs.ListTasksOutput{TaskArns: []string{"arn:aws:ecs:ap-northeast-1:1:task/main/3f2a9c0d1e"}},
+		describeTasksOutput: ecs.DescribeTasksOutput{Tasks: []types.Task{{
+			TaskArn:     aws.String("arn:aws:ecs:ap-northeast-1:1:task/main/3f2a9c0d1e"),
+			Group:       aws.String("service:api"),
+			Attachments: []types.Attachment{eni},
+		}}},
+	}
+	mockService := awshelper.EcsService{Service: mockEcsService}
+	for _, query := range []string{"10.0.12.34", "eni-0123456789abcdef0", "3f2a"} {
+		found, err := mockService.FindTasks(nil, query)
+		if err != nil {
+			t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+		}
+		if len(found) != 1 || found[0].Cluster != "main" || found[0].Service != "api" || found[0].Id != "3f2a9c0d1e" {
+			t.Errorf("%s の検索結果が期待値と異なります：%+v", query, found)
+		}
+	}
+	found, err := mockService.FindTasks([]string{"main"}, "4b")
+	if err != nil || len(found) != 0 {
+		t.Errorf("一致しないタスクが検索されています：%+v", found)
+	}
+
+	mockEcsService.err = errors.New("error")
+	if _, err := mockService.FindTasks(nil, "3f2a"); err == nil {
+		t.Error("関数の戻り値にエラーが含まれていません。")
+	}
+}
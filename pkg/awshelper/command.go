@@ -0,0 +1,76 @@
+package awshelper
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+func CommandWithEnv(command string, env map[string]string) string {
+	if len(env) == 0 {
+		return command
+	}
+	args := []string{"env"}
+	for _, k := range sortedKeys(env) {
+		args = append(args, k+"="+Quote(env[k]))
+	}
+	return strings.Join(append(args, command), " ")
+}
+
+func PowerShellWithEnv(command string, env map[string]string) string {
+	var script strings.Builder
+	for _, k := range sortedKeys(env) {
+		script.WriteString("$env:" + k + " = " + PowerShellQuote(env[k]) + "; ")
+	}
+	return script.String() + command
+}
+
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func ShellCommand(shell string, script string) string {
+	return shell + " -c " + Quote(script)
+}
+
+// -- の後に指定した引数を、リモートのシェルで分割や展開をされずに元の引数のまま渡るよう連結する
+func JoinArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, Quote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// 引用符で囲んだコマンド名は文字列として扱われるため、呼び出し演算子で実行する
+func PowerShellJoinArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, PowerShellQuote(arg))
+	}
+	return "& " + strings.Join(quoted, " ")
+}
+
+func PowerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Windows ではコマンドラインの引用符の解釈がプログラムごとに異なるため、スクリプトは UTF-16LE の base64 で渡す
+func PowerShellCommand(script string) string {
+	units := utf16.Encode([]rune(script))
+	data := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		data = append(data, byte(u), byte(u>>8))
+	}
+	return WindowsShell + " -NoLogo -NoProfile -EncodedCommand " + base64.StdEncoding.EncodeToString(data)
+}
@@ -26,13 +26,20 @@ func NewConfigService() ConfigService {
 	return ConfigService{Service: &configLoader{}}
 }
 
+func ResolveProfile(profile string) string {
+	if os.Getenv("AWS_SESSION_TOKEN") != "" {
+		return ""
+	}
+	if profile == "default" && os.Getenv("AWS_DEFAULT_PROFILE") != "" {
+		return os.Getenv("AWS_DEFAULT_PROFILE")
+	}
+	return profile
+}
+
 func (configService *ConfigService) FindAWSCredential(profile string) (aws.Config, error) {
-	awsProfile := profile
+	awsProfile := ResolveProfile(profile)
 
-	if os.Getenv("AWS_SESSION_TOKEN") == "" {
-		if awsProfile == "default" && os.Getenv("AWS_DEFAULT_PROFILE") != "" {
-			awsProfile = os.Getenv("AWS_DEFAULT_PROFILE")
-		}
+	if awsProfile != "" {
 		awsCfg, err := configService.Service.LoadDefaultConfig(
 			context.TODO(),
 			config.WithSharedConfigProfile(awsProfile),
@@ -2,6 +2,7 @@ package awshelper
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 
@@ -10,10 +11,16 @@ import (
 )
 
 const (
-	maxCount = 50
+	DefaultMaxCount = 50
+	DefaultShell    = "/bin/sh"
+	DefaultCommand  = DefaultShell
+	WindowsShell    = "powershell.exe"
 )
 
 type iFEcsService interface {
+	DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error)
+	DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error)
+	DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error)
 	ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error)
 	ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error)
 	ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error)
@@ -22,20 +29,28 @@ type iFEcsService interface {
 }
 
 type EcsService struct {
-	Service iFEcsService
+	Service  iFEcsService
+	MaxCount int
 }
 
 func (ecsService *EcsService) SetEcsClient(cfg aws.Config) {
 	ecsService.Service = ecs.NewFromConfig(cfg)
 }
 
+func (ecsService *EcsService) maxResults() *int32 {
+	if ecsService.MaxCount > 0 {
+		return aws.Int32(int32(ecsService.MaxCount))
+	}
+	return aws.Int32(DefaultMaxCount)
+}
+
 func (ecsService *EcsService) GetClusters() (clusters []string, err error) {
 	params := &ecs.ListClustersInput{
-		MaxResults: aws.Int32(maxCount),
+		MaxResults: ecsService.maxResults(),
 	}
 	resp, err := ecsService.Service.ListClusters(context.TODO(), params)
 	if err != nil {
-		return nil, err
+		return nil, wrapError("ecs:ListClusters", err)
 	}
 
 	for _, v := range resp.ClusterArns {
@@ -49,11 +64,11 @@ func (ecsService *EcsService) GetClusters() (clusters []string, err error) {
 func (ecsService *EcsService) GetServices(cluster string) (services []string, err error) {
 	params := &ecs.ListServicesInput{
 		Cluster:    aws.String(cluster),
-		MaxResults: aws.Int32(maxCount),
+		MaxResults: ecsService.maxResults(),
 	}
 	resp, err := ecsService.Service.ListServices(context.TODO(), params)
 	if err != nil {
-		return nil, err
+		return nil, wrapError("ecs:ListServices", err)
 	}
 	for _, v := range resp.ServiceArns {
 		services = append(services, strings.Split(v, "/")[len(strings.Split(v, "/"))-1])
@@ -64,16 +79,21 @@ func (ecsService *EcsService) GetServices(cluster string) (services []string, er
 
 func (ecsService *EcsService) GetTasks(cluster string, service string) (tasks []string, err error) {
 	params := &ecs.ListTasksInput{
-		Cluster:     aws.String(cluster),
-		MaxResults:  aws.Int32(maxCount),
-		ServiceName: aws.String(service),
+		Cluster:    aws.String(cluster),
+		MaxResults: ecsService.maxResults(),
 	}
-	resp, err := ecsService.Service.ListTasks(context.TODO(), params)
-	if len(resp.TaskArns) <= 0 || err != nil {
-		return nil, err
+	if service != "" {
+		params.ServiceName = aws.String(service)
 	}
-	for _, v := range resp.TaskArns {
-		tasks = append(tasks, strings.Split(v, "/")[len(strings.Split(v, "/"))-1])
+	paginator := ecs.NewListTasksPaginator(ecsService.Service, params)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, wrapError("ecs:ListTasks", err)
+		}
+		for _, v := range resp.TaskArns {
+			tasks = append(tasks, strings.Split(v, "/")[len(strings.Split(v, "/"))-1])
+		}
 	}
 	return tasks, nil
 }
@@ -85,8 +105,11 @@ func (ecsService *EcsService) GetContainers(cluster string, task string) (contai
 		Cluster: aws.String(cluster),
 	}
 	resp, err := ecsService.Service.DescribeTasks(context.TODO(), params)
-	if len(resp.Tasks[0].Containers) <= 0 || err != nil {
-		return nil, err
+	if err != nil {
+		return nil, wrapError("ecs:DescribeTasks", err)
+	}
+	if len(resp.Tasks) <= 0 || len(resp.Tasks[0].Containers) <= 0 {
+		return nil, nil
 	}
 	for _, v := range resp.Tasks[0].Containers {
 		containers = append(containers, *v.Name)
@@ -95,17 +118,93 @@ func (ecsService *EcsService) GetContainers(cluster string, task string) (contai
 	return containers, nil
 }
 
-func (ecsService *EcsService) ExecuteContainer(cluster string, task string, container string) (*ecs.ExecuteCommandOutput, error) {
+func (ecsService *EcsService) GetContainerRuntimeId(cluster string, task string, container string) (string, error) {
+	params := &ecs.DescribeTasksInput{
+		Tasks:   []string{task},
+		Cluster: aws.String(cluster),
+	}
+	resp, err := ecsService.Service.DescribeTasks(context.TODO(), params)
+	if err != nil {
+		return "", wrapError("ecs:DescribeTasks", err)
+	}
+	for _, t := range resp.Tasks {
+		for _, c := range t.Containers {
+			if aws.ToString(c.Name) == container {
+				return aws.ToString(c.RuntimeId), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("container %s not found in task %s", container, task)
+}
+
+func (ecsService *EcsService) GetTaskStatus(cluster string, task string) (string, error) {
+	params := &ecs.DescribeTasksInput{
+		Tasks:   []string{task},
+		Cluster: aws.String(cluster),
+	}
+	resp, err := ecsService.Service.DescribeTasks(context.TODO(), params)
+	if err != nil {
+		return "", wrapError("ecs:DescribeTasks", err)
+	}
+	if len(resp.Tasks) <= 0 {
+		return "", nil
+	}
+	return aws.ToString(resp.Tasks[0].LastStatus), nil
+}
+
+type TaskPlatform struct {
+	TaskDefinitionArn string
+	Family            string
+}
+
+func (platform TaskPlatform) Windows() bool {
+	return strings.HasPrefix(strings.ToUpper(platform.Family), "WINDOWS")
+}
+
+// EC2 起動タイプではタスクに platformFamily が設定されないため、タスク定義の runtimePlatform を参照する
+func (ecsService *EcsService) GetTaskPlatform(cluster string, task string) (TaskPlatform, error) {
+	resp, err := ecsService.Service.DescribeTasks(context.TODO(), &ecs.DescribeTasksInput{
+		Tasks:   []string{task},
+		Cluster: aws.String(cluster),
+	})
+	if err != nil {
+		return TaskPlatform{}, wrapError("ecs:DescribeTasks", err)
+	}
+	if len(resp.Tasks) <= 0 {
+		return TaskPlatform{}, fmt.Errorf("task %s not found", task)
+	}
+	return ecsService.TaskInfoPlatform(newTaskInfo(resp.Tasks[0])), nil
+}
+
+// DescribeTasks で取得済みのタスクからプラットフォームを判定する
+func (ecsService *EcsService) TaskInfoPlatform(task TaskInfo) TaskPlatform {
+	platform := TaskPlatform{
+		TaskDefinitionArn: task.TaskDefinitionArn,
+		Family:            task.PlatformFamily,
+	}
+	if platform.Family != "" {
+		return platform
+	}
+	def, err := ecsService.Service.DescribeTaskDefinition(context.TODO(), &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(platform.TaskDefinitionArn),
+	})
+	if err == nil && def.TaskDefinition != nil && def.TaskDefinition.RuntimePlatform != nil {
+		platform.Family = string(def.TaskDefinition.RuntimePlatform.OperatingSystemFamily)
+	}
+	return platform
+}
+
+func (ecsService *EcsService) ExecuteContainer(cluster string, task string, container string, command string) (*ecs.ExecuteCommandOutput, error) {
 	params := &ecs.ExecuteCommandInput{
 		Cluster:     aws.String(cluster),
-		Command:     aws.String("/bin/sh"),
+		Command:     aws.String(command),
 		Container:   aws.String(container),
 		Interactive: true,
 		Task:        aws.String(task),
 	}
 	resp, err := ecsService.Service.ExecuteCommand(context.TODO(), params)
 	if err != nil {
-		return nil, err
+		return nil, wrapError("ecs:ExecuteCommand", err)
 	}
 	return resp, nil
 }
@@ -0,0 +1,74 @@
+package awshelper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	ErrAccessDenied       = errors.New("access denied")
+	ErrClusterNotFound    = errors.New("cluster not found")
+	ErrExecDisabled       = errors.New("execute command is not enabled")
+	ErrTargetNotConnected = errors.New("execute command agent is not connected")
+	ErrExpiredToken       = errors.New("credentials have expired")
+	ErrThrottled          = errors.New("request was throttled")
+)
+
+var errorCodes = map[string]error{
+	"AccessDeniedException":       ErrAccessDenied,
+	"AccessDenied":                ErrAccessDenied,
+	"UnauthorizedOperation":       ErrAccessDenied,
+	"ClusterNotFoundException":    ErrClusterNotFound,
+	"TargetNotConnectedException": ErrTargetNotConnected,
+	"ExpiredToken":                ErrExpiredToken,
+	"ExpiredTokenException":       ErrExpiredToken,
+	"RequestExpired":              ErrExpiredToken,
+	"ThrottlingException":         ErrThrottled,
+	"Throttling":                  ErrThrottled,
+	"TooManyRequestsException":    ErrThrottled,
+	"RequestLimitExceeded":        ErrThrottled,
+}
+
+type APIError struct {
+	Kind      error
+	Action    string
+	Code      string
+	RequestId string
+	Err       error
+}
+
+func (e *APIError) Error() string {
+	if e.Kind != nil {
+		return fmt.Sprintf("%s: %v: %v", e.Action, e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Action, e.Err)
+}
+
+func (e *APIError) Unwrap() []error {
+	if e.Kind != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// SDK のエラーコードを種類ごとのエラーに変換し、IAM アクションとリクエスト ID を付与する
+func wrapError(action string, err error) error {
+	apiErr := &APIError{Action: action, Err: err}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		apiErr.RequestId = respErr.ServiceRequestID()
+	}
+	var smithyErr smithy.APIError
+	if errors.As(err, &smithyErr) {
+		apiErr.Code = smithyErr.ErrorCode()
+		apiErr.Kind = errorCodes[apiErr.Code]
+		if apiErr.Code == "InvalidParameterException" && strings.Contains(strings.ToLower(smithyErr.ErrorMessage()), "execute command") {
+			apiErr.Kind = ErrExecDisabled
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,108 @@
+package awshelper
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+const (
+	listLimit     = 100
+	eniPrefix     = "eni-"
+	servicePrefix = "service:"
+)
+
+type FoundTask struct {
+	Cluster  string `json:"cluster" yaml:"cluster"`
+	Service  string `json:"service" yaml:"service"`
+	TaskInfo `yaml:",inline"`
+}
+
+// プライベート IP・ENI ID・タスク ID の前方一致のいずれかでタスクを判定する
+func MatchTask(task TaskInfo, query string) bool {
+	switch {
+	case strings.HasPrefix(query, eniPrefix):
+		return task.NetworkInterfaceId == query
+	case net.ParseIP(query) != nil:
+		return task.PrivateIp == query
+	default:
+		return query != "" && strings.HasPrefix(task.Id, strings.ToLower(query))
+	}
+}
+
+// クラスターを指定しない場合はアカウント内の全てのクラスターの稼働中のタスクから検索する
+func (ecsService *EcsService) FindTasks(clusters []string, query string) ([]FoundTask, error) {
+	if len(clusters) <= 0 {
+		var err error
+		if clusters, err = ecsService.listAllClusters(); err != nil {
+			return nil, err
+		}
+	}
+	// タスク ID の前方一致は ARN だけで判定できるため、一致するタスクのみ DescribeTasks で取得する
+	byId := !strings.HasPrefix(query, eniPrefix) && net.ParseIP(query) == nil
+	var found []FoundTask
+	for _, cluster := range clusters {
+		arns, err := ecsService.listAllTasks(cluster)
+		if err != nil {
+			return nil, err
+		}
+		if byId {
+			var candidates []string
+			for _, arn := range arns {
+				if strings.HasPrefix(resourceName(arn), strings.ToLower(query)) {
+					candidates = append(candidates, arn)
+				}
+			}
+			arns = candidates
+		}
+		if len(arns) <= 0 {
+			continue
+		}
+		tasks, err := ecsService.DescribeTaskInfos(cluster, arns)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			if MatchTask(t, query) {
+				service, _ := strings.CutPrefix(t.Group, servicePrefix)
+				found = append(found, FoundTask{Cluster: cluster, Service: service, TaskInfo: t})
+			}
+		}
+	}
+	return found, nil
+}
+
+func (ecsService *EcsService) listAllClusters() (clusters []string, err error) {
+	params := &ecs.ListClustersInput{MaxResults: aws.Int32(listLimit)}
+	for {
+		resp, err := ecsService.Service.ListClusters(context.TODO(), params)
+		if err != nil {
+			return nil, wrapError("ecs:ListClusters", err)
+		}
+		for _, v := range resp.ClusterArns {
+			clusters = append(clusters, resourceName(v))
+		}
+		if resp.NextToken == nil {
+			return clusters, nil
+		}
+		params.NextToken = resp.NextToken
+	}
+}
+
+func (ecsService *EcsService) listAllTasks(cluster string) (arns []string, err error) {
+	params := &ecs.ListTasksInput{Cluster: aws.String(cluster), MaxResults: aws.Int32(listLimit)}
+	for {
+		resp, err := ecsService.Service.ListTasks(context.TODO(), params)
+		if err != nil {
+			return nil, wrapError("ecs:ListTasks", err)
+		}
+		arns = append(arns, resp.TaskArns...)
+		if resp.NextToken == nil {
+			return arns, nil
+		}
+		params.NextToken = resp.NextToken
+	}
+}
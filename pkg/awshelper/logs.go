@@ -0,0 +1,105 @@
+package awshelper
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	awslogsGroup        = "awslogs-group"
+	awslogsStreamPrefix = "awslogs-stream-prefix"
+)
+
+type iFLogsService interface {
+	GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error)
+}
+
+type LogsService struct {
+	Service iFLogsService
+}
+
+type LogStream struct {
+	Group  string
+	Stream string
+}
+
+type LogEvent struct {
+	Timestamp time.Time
+	Message   string
+}
+
+func (logsService *LogsService) SetLogsClient(cfg aws.Config) {
+	logsService.Service = cloudwatchlogs.NewFromConfig(cfg)
+}
+
+func (logsService *LogsService) GetLogEvents(stream LogStream, limit int32) (events []LogEvent, err error) {
+	events, _, err = logsService.GetLogEventsPage(stream, limit, "")
+	return events, err
+}
+
+// トークンを指定しない場合は末尾から取得し、返却したトークンで以降のログを取得できる
+func (logsService *LogsService) GetLogEventsPage(stream LogStream, limit int32, token string) (events []LogEvent, next string, err error) {
+	params := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(stream.Group),
+		LogStreamName: aws.String(stream.Stream),
+		StartFromHead: aws.Bool(false),
+	}
+	if limit > 0 {
+		params.Limit = aws.Int32(limit)
+	}
+	if token != "" {
+		params.NextToken = aws.String(token)
+		params.StartFromHead = aws.Bool(true)
+	}
+	resp, err := logsService.Service.GetLogEvents(context.TODO(), params)
+	if err != nil {
+		return nil, "", wrapError("logs:GetLogEvents", err)
+	}
+	for _, v := range resp.Events {
+		events = append(events, LogEvent{
+			Timestamp: time.UnixMilli(aws.ToInt64(v.Timestamp)),
+			Message:   aws.ToString(v.Message),
+		})
+	}
+	return events, aws.ToString(resp.NextForwardToken), nil
+}
+
+func (ecsService *EcsService) GetLogStream(cluster string, task string, container string) (LogStream, error) {
+	tasks, err := ecsService.Service.DescribeTasks(context.TODO(), &ecs.DescribeTasksInput{
+		Cluster: aws.String(cluster),
+		Tasks:   []string{task},
+	})
+	if err != nil {
+		return LogStream{}, wrapError("ecs:DescribeTasks", err)
+	}
+	if len(tasks.Tasks) <= 0 {
+		return LogStream{}, fmt.Errorf("task %s not found", task)
+	}
+	resp, err := ecsService.Service.DescribeTaskDefinition(context.TODO(), &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: tasks.Tasks[0].TaskDefinitionArn,
+	})
+	if err != nil {
+		return LogStream{}, wrapError("ecs:DescribeTaskDefinition", err)
+	}
+	for _, v := range resp.TaskDefinition.ContainerDefinitions {
+		if aws.ToString(v.Name) != container {
+			continue
+		}
+		if v.LogConfiguration == nil || v.LogConfiguration.LogDriver != types.LogDriverAwslogs {
+			return LogStream{}, fmt.Errorf("container %s does not use the awslogs log driver", container)
+		}
+		options := v.LogConfiguration.Options
+		return LogStream{
+			Group:  options[awslogsGroup],
+			Stream: path.Join(options[awslogsStreamPrefix], container, resourceName(aws.ToString(tasks.Tasks[0].TaskArn))),
+		}, nil
+	}
+	return LogStream{}, fmt.Errorf("container %s not found in task definition", container)
+}
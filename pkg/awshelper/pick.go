@@ -0,0 +1,156 @@
+package awshelper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+const (
+	PickPrompt    = "prompt"
+	PickFirst     = "first"
+	PickRandom    = "random"
+	PickNewest    = "newest"
+	PickOldest    = "oldest"
+	PickHealthy   = "healthy"
+	PickAz        = "az="
+	PickIp        = "ip="
+	PickLatest    = "revision=latest"
+	LatestRev     = "latest"
+	pickRev       = "revision="
+	healthyStatus = "HEALTHY"
+	primaryStatus = "PRIMARY"
+)
+
+// タスクの選択方法。絞り込みの条件と選び方をカンマ区切りで組み合わせて指定する
+type TaskPick struct {
+	Order    string
+	Healthy  bool
+	Zone     string
+	Ip       string
+	Revision string
+}
+
+func ParsePick(s string) (TaskPick, error) {
+	pick := TaskPick{}
+	if s == "" {
+		pick.Order = PickPrompt
+		return pick, nil
+	}
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		switch {
+		case term == PickPrompt, term == PickFirst, term == PickRandom, term == PickNewest, term == PickOldest:
+			if pick.Order != "" {
+				return pick, fmt.Errorf("multiple orders in pick strategy: %s", s)
+			}
+			pick.Order = term
+		case term == PickHealthy:
+			pick.Healthy = true
+		case strings.HasPrefix(term, PickAz) && term != PickAz:
+			pick.Zone = strings.TrimPrefix(term, PickAz)
+		case strings.HasPrefix(term, PickIp) && term != PickIp:
+			pick.Ip = strings.TrimPrefix(term, PickIp)
+		case term == PickLatest:
+			pick.Revision = LatestRev
+		case strings.HasPrefix(term, pickRev):
+			if _, err := strconv.Atoi(strings.TrimPrefix(term, pickRev)); err != nil {
+				return pick, fmt.Errorf("unsupported pick strategy: %s", term)
+			}
+			pick.Revision = strings.TrimPrefix(term, pickRev)
+		default:
+			return pick, fmt.Errorf("unsupported pick strategy: %s", term)
+		}
+	}
+	// 条件だけを指定した場合は、一致したタスクからランダムに選ぶ
+	if pick.Order == "" {
+		pick.Order = PickRandom
+	}
+	return pick, nil
+}
+
+// ブックマークや履歴で接続先を決めた場合は、選択方法の指定がなければタスクを選ばずに先頭のタスクに接続する
+func DefaultPick(pick string) string {
+	if pick == "" {
+		return PickFirst
+	}
+	return pick
+}
+
+// 絞り込みや起動日時での並び替えには DescribeTasks で取得するタスクの情報が必要になる
+func (pick TaskPick) NeedsDetails() bool {
+	return pick.Healthy || pick.Zone != "" || pick.Ip != "" || pick.Revision != "" || pick.Order == PickNewest || pick.Order == PickOldest
+}
+
+// 条件に一致するタスクを返す。newest・oldest の場合は選ぶ順に並べる
+// revision=latest を PrimaryRevision で解決していない場合は、稼働中のタスクの最大のリビジョンとする
+func (pick TaskPick) Filter(tasks []TaskInfo) []TaskInfo {
+	revision := pick.Revision
+	if revision == LatestRev {
+		latest := 0
+		for _, t := range tasks {
+			latest = max(latest, arnRevision(t.TaskDefinitionArn))
+		}
+		revision = strconv.Itoa(latest)
+	}
+	var matched []TaskInfo
+	for _, t := range tasks {
+		if pick.Healthy && t.HealthStatus != healthyStatus {
+			continue
+		}
+		if pick.Zone != "" && t.AvailabilityZone != pick.Zone {
+			continue
+		}
+		if pick.Ip != "" && t.PrivateIp != pick.Ip {
+			continue
+		}
+		if revision != "" && strconv.Itoa(arnRevision(t.TaskDefinitionArn)) != revision {
+			continue
+		}
+		matched = append(matched, t)
+	}
+	switch pick.Order {
+	case PickNewest:
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].StartedAt.After(matched[j].StartedAt) })
+	case PickOldest:
+		sort.SliceStable(matched, func(i, j int) bool { return matched[i].StartedAt.Before(matched[j].StartedAt) })
+	}
+	return matched
+}
+
+// ロールバック中は稼働中のタスクの最大のリビジョンが最新とは限らないため、
+// サービスの PRIMARY デプロイのタスク定義のリビジョンを返す。デプロイが見つからない場合は空文字
+func (ecsService *EcsService) PrimaryRevision(cluster string, service string) (string, error) {
+	resp, err := ecsService.Service.DescribeServices(context.TODO(), &ecs.DescribeServicesInput{
+		Cluster:  aws.String(cluster),
+		Services: []string{service},
+	})
+	if err != nil {
+		return "", wrapError("ecs:DescribeServices", err)
+	}
+	for _, s := range resp.Services {
+		for _, d := range s.Deployments {
+			if aws.ToString(d.Status) == primaryStatus {
+				return strconv.Itoa(arnRevision(aws.ToString(d.TaskDefinition))), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func arnRevision(taskDefinitionArn string) int {
+	i := strings.LastIndex(taskDefinitionArn, ":")
+	if i < 0 {
+		return 0
+	}
+	revision, err := strconv.Atoi(taskDefinitionArn[i+1:])
+	if err != nil {
+		return 0
+	}
+	return revision
+}
@@ -0,0 +1,56 @@
+package awshelper
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// 共有設定ファイルは [profile 名前]、認証情報ファイルは [名前] の形式でプロファイルを定義する
+func ListProfiles() []string {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = config.DefaultSharedConfigFilename()
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = config.DefaultSharedCredentialsFilename()
+	}
+
+	found := map[string]bool{}
+	for _, name := range readSections(configFile) {
+		if name == "default" {
+			found[name] = true
+		} else if profile, ok := strings.CutPrefix(name, "profile "); ok {
+			found[strings.TrimSpace(profile)] = true
+		}
+	}
+	for _, name := range readSections(credentialsFile) {
+		found[name] = true
+	}
+	profiles := make([]string, 0, len(found))
+	for name := range found {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+func readSections(path string) (sections []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, strings.TrimSpace(line[1:len(line)-1]))
+		}
+	}
+	return sections
+}
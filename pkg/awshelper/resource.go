@@ -0,0 +1,339 @@
+package awshelper
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	describeClustersLimit = 100
+	describeServicesLimit = 10
+	describeTasksLimit    = 100
+	executeCommandAgent   = "ExecuteCommandAgent"
+)
+
+type ClusterInfo struct {
+	Name           string `json:"name" yaml:"name"`
+	Arn            string `json:"arn" yaml:"arn"`
+	Status         string `json:"status" yaml:"status"`
+	ActiveServices int32  `json:"activeServices" yaml:"activeServices"`
+	RunningTasks   int32  `json:"runningTasks" yaml:"runningTasks"`
+	PendingTasks   int32  `json:"pendingTasks" yaml:"pendingTasks"`
+}
+
+type ServiceInfo struct {
+	Name                 string `json:"name" yaml:"name"`
+	Arn                  string `json:"arn" yaml:"arn"`
+	Status               string `json:"status" yaml:"status"`
+	TaskDefinition       string `json:"taskDefinition" yaml:"taskDefinition"`
+	LaunchType           string `json:"launchType" yaml:"launchType"`
+	DesiredCount         int32  `json:"desiredCount" yaml:"desiredCount"`
+	RunningCount         int32  `json:"runningCount" yaml:"runningCount"`
+	PendingCount         int32  `json:"pendingCount" yaml:"pendingCount"`
+	EnableExecuteCommand bool   `json:"enableExecuteCommand" yaml:"enableExecuteCommand"`
+}
+
+type TaskInfo struct {
+	Id                   string    `json:"id" yaml:"id"`
+	Arn                  string    `json:"arn" yaml:"arn"`
+	LastStatus           string    `json:"lastStatus" yaml:"lastStatus"`
+	DesiredStatus        string    `json:"desiredStatus" yaml:"desiredStatus"`
+	HealthStatus         string    `json:"healthStatus" yaml:"healthStatus"`
+	TaskDefinitionArn    string    `json:"taskDefinitionArn" yaml:"taskDefinitionArn"`
+	AvailabilityZone     string    `json:"availabilityZone" yaml:"availabilityZone"`
+	LaunchType           string    `json:"launchType" yaml:"launchType"`
+	PlatformFamily       string    `json:"platformFamily" yaml:"platformFamily"`
+	PrivateIp            string    `json:"privateIp" yaml:"privateIp"`
+	NetworkInterfaceId   string    `json:"networkInterfaceId" yaml:"networkInterfaceId"`
+	Group                string    `json:"group" yaml:"group"`
+	StartedAt            time.Time `json:"startedAt" yaml:"startedAt"`
+	EnableExecuteCommand bool      `json:"enableExecuteCommand" yaml:"enableExecuteCommand"`
+}
+
+type ContainerInfo struct {
+	Name         string `json:"name" yaml:"name"`
+	Arn          string `json:"arn" yaml:"arn"`
+	Image        string `json:"image" yaml:"image"`
+	LastStatus   string `json:"lastStatus" yaml:"lastStatus"`
+	HealthStatus string `json:"healthStatus" yaml:"healthStatus"`
+	RuntimeId    string `json:"runtimeId" yaml:"runtimeId"`
+	ExecAgent    string `json:"execAgent" yaml:"execAgent"`
+	Essential    bool   `json:"essential" yaml:"essential"`
+}
+
+func (ecsService *EcsService) ListClusterInfos() (clusters []ClusterInfo, err error) {
+	params := &ecs.ListClustersInput{
+		MaxResults: ecsService.maxResults(),
+	}
+	var clusterArns []string
+	paginator := ecs.NewListClustersPaginator(ecsService.Service, params)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, wrapError("ecs:ListClusters", err)
+		}
+		clusterArns = append(clusterArns, resp.ClusterArns...)
+	}
+	if len(clusterArns) <= 0 {
+		return nil, nil
+	}
+	clusters, err = ecsService.DescribeClusterInfos(clusterArns)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+	return clusters, nil
+}
+
+func (ecsService *EcsService) DescribeClusterInfos(clusterArns []string) (clusters []ClusterInfo, err error) {
+	for _, arns := range chunk(clusterArns, describeClustersLimit) {
+		resp, err := ecsService.Service.DescribeClusters(context.TODO(), &ecs.DescribeClustersInput{
+			Clusters: arns,
+		})
+		if err != nil {
+			return nil, wrapError("ecs:DescribeClusters", err)
+		}
+		for _, v := range resp.Clusters {
+			clusters = append(clusters, ClusterInfo{
+				Name:           aws.ToString(v.ClusterName),
+				Arn:            aws.ToString(v.ClusterArn),
+				Status:         aws.ToString(v.Status),
+				ActiveServices: v.ActiveServicesCount,
+				RunningTasks:   v.RunningTasksCount,
+				PendingTasks:   v.PendingTasksCount,
+			})
+		}
+	}
+	return clusters, nil
+}
+
+func (ecsService *EcsService) ListServiceInfos(cluster string) (services []ServiceInfo, err error) {
+	params := &ecs.ListServicesInput{
+		Cluster:    aws.String(cluster),
+		MaxResults: ecsService.maxResults(),
+	}
+	var serviceArns []string
+	paginator := ecs.NewListServicesPaginator(ecsService.Service, params)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, wrapError("ecs:ListServices", err)
+		}
+		serviceArns = append(serviceArns, resp.ServiceArns...)
+	}
+	services, err = ecsService.DescribeServiceInfos(cluster, serviceArns)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+func (ecsService *EcsService) DescribeServiceInfos(cluster string, serviceArns []string) (services []ServiceInfo, err error) {
+	for _, arns := range chunk(serviceArns, describeServicesLimit) {
+		resp, err := ecsService.Service.DescribeServices(context.TODO(), &ecs.DescribeServicesInput{
+			Cluster:  aws.String(cluster),
+			Services: arns,
+		})
+		if err != nil {
+			return nil, wrapError("ecs:DescribeServices", err)
+		}
+		for _, v := range resp.Services {
+			services = append(services, ServiceInfo{
+				Name:                 aws.ToString(v.ServiceName),
+				Arn:                  aws.ToString(v.ServiceArn),
+				Status:               aws.ToString(v.Status),
+				TaskDefinition:       resourceName(aws.ToString(v.TaskDefinition)),
+				LaunchType:           string(v.LaunchType),
+				DesiredCount:         v.DesiredCount,
+				RunningCount:         v.RunningCount,
+				PendingCount:         v.PendingCount,
+				EnableExecuteCommand: v.EnableExecuteCommand,
+			})
+		}
+	}
+	return services, nil
+}
+
+func (ecsService *EcsService) ListTaskInfos(cluster string, service string) ([]TaskInfo, error) {
+	params := &ecs.ListTasksInput{
+		Cluster:    aws.String(cluster),
+		MaxResults: ecsService.maxResults(),
+	}
+	if service != "" {
+		params.ServiceName = aws.String(service)
+	}
+	var taskArns []string
+	paginator := ecs.NewListTasksPaginator(ecsService.Service, params)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, wrapError("ecs:ListTasks", err)
+		}
+		taskArns = append(taskArns, resp.TaskArns...)
+	}
+	tasks, err := ecsService.DescribeTaskInfos(cluster, taskArns)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StartedAt.After(tasks[j].StartedAt) })
+	return tasks, nil
+}
+
+func (ecsService *EcsService) DescribeTaskInfos(cluster string, taskArns []string) (tasks []TaskInfo, err error) {
+	for _, arns := range chunk(taskArns, describeTasksLimit) {
+		resp, err := ecsService.Service.DescribeTasks(context.TODO(), &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   arns,
+		})
+		if err != nil {
+			return nil, wrapError("ecs:DescribeTasks", err)
+		}
+		for _, v := range resp.Tasks {
+			tasks = append(tasks, newTaskInfo(v))
+		}
+	}
+	return tasks, nil
+}
+
+func (ecsService *EcsService) ListContainerInfos(cluster string, task string) (containers []ContainerInfo, err error) {
+	resp, err := ecsService.Service.DescribeTasks(context.TODO(), &ecs.DescribeTasksInput{
+		Cluster: aws.String(cluster),
+		Tasks:   []string{task},
+	})
+	if err != nil {
+		return nil, wrapError("ecs:DescribeTasks", err)
+	}
+	if len(resp.Tasks) <= 0 {
+		return nil, nil
+	}
+	essential := ecsService.essentialContainers(aws.ToString(resp.Tasks[0].TaskDefinitionArn))
+	for _, v := range resp.Tasks[0].Containers {
+		container := ContainerInfo{
+			Name:         aws.ToString(v.Name),
+			Arn:          aws.ToString(v.ContainerArn),
+			Image:        aws.ToString(v.Image),
+			LastStatus:   aws.ToString(v.LastStatus),
+			HealthStatus: string(v.HealthStatus),
+			RuntimeId:    aws.ToString(v.RuntimeId),
+			Essential:    true,
+		}
+		if value, ok := essential[container.Name]; ok {
+			container.Essential = value
+		}
+		for _, agent := range v.ManagedAgents {
+			if string(agent.Name) == executeCommandAgent {
+				container.ExecAgent = aws.ToString(agent.LastStatus)
+			}
+		}
+		containers = append(containers, container)
+	}
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+	return containers, nil
+}
+
+// タスク定義を取得できない場合は、ECS の初期値と同じく全てのコンテナを必須として扱う
+func (ecsService *EcsService) essentialContainers(taskDefinition string) map[string]bool {
+	resp, err := ecsService.Service.DescribeTaskDefinition(context.TODO(), &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(taskDefinition),
+	})
+	if err != nil || resp.TaskDefinition == nil {
+		return nil
+	}
+	essential := map[string]bool{}
+	for _, v := range resp.TaskDefinition.ContainerDefinitions {
+		essential[aws.ToString(v.Name)] = v.Essential == nil || *v.Essential
+	}
+	return essential
+}
+
+// サイドカーを除いたコンテナ、その中の必須コンテナの順に絞り込み、一つに決まる場合はそのコンテナを返す
+func AppContainer(containers []ContainerInfo, sidecars []string) (string, bool) {
+	var apps []ContainerInfo
+	for _, c := range containers {
+		if !isSidecar(c.Name, sidecars) {
+			apps = append(apps, c)
+		}
+	}
+	if len(apps) == 1 {
+		return apps[0].Name, true
+	}
+	var essential []ContainerInfo
+	for _, c := range apps {
+		if c.Essential {
+			essential = append(essential, c)
+		}
+	}
+	if len(essential) == 1 {
+		return essential[0].Name, true
+	}
+	return "", false
+}
+
+func isSidecar(name string, sidecars []string) bool {
+	for _, pattern := range sidecars {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newTaskInfo(task types.Task) TaskInfo {
+	info := TaskInfo{
+		Id:                   resourceName(aws.ToString(task.TaskArn)),
+		Arn:                  aws.ToString(task.TaskArn),
+		LastStatus:           aws.ToString(task.LastStatus),
+		DesiredStatus:        aws.ToString(task.DesiredStatus),
+		HealthStatus:         string(task.HealthStatus),
+		TaskDefinitionArn:    aws.ToString(task.TaskDefinitionArn),
+		AvailabilityZone:     aws.ToString(task.AvailabilityZone),
+		LaunchType:           string(task.LaunchType),
+		PlatformFamily:       aws.ToString(task.PlatformFamily),
+		Group:                aws.ToString(task.Group),
+		StartedAt:            aws.ToTime(task.StartedAt),
+		EnableExecuteCommand: task.EnableExecuteCommand,
+	}
+	for _, c := range task.Containers {
+		for _, ni := range c.NetworkInterfaces {
+			if info.PrivateIp == "" {
+				info.PrivateIp = aws.ToString(ni.PrivateIpv4Address)
+			}
+		}
+	}
+	for _, a := range task.Attachments {
+		for _, d := range a.Details {
+			switch aws.ToString(d.Name) {
+			case "networkInterfaceId":
+				info.NetworkInterfaceId = aws.ToString(d.Value)
+			case "privateIPv4Address":
+				if info.PrivateIp == "" {
+					info.PrivateIp = aws.ToString(d.Value)
+				}
+			}
+		}
+	}
+	return info
+}
+
+func resourceName(arn string) string {
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
+
+func chunk(items []string, size int) (chunks [][]string) {
+	for size < len(items) {
+		chunks = append(chunks, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
@@ -0,0 +1,49 @@
+package awshelper
+
+import (
+	"context"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type iFS3Service interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+type S3Service struct {
+	Service  iFS3Service
+	Bucket   string
+	Prefix   string
+	KmsKeyId string
+}
+
+func (s3Service *S3Service) SetS3Client(cfg aws.Config, endpoint string) {
+	s3Service.Service = s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+}
+
+func (s3Service *S3Service) Upload(key string, body io.Reader) error {
+	params := &s3.PutObjectInput{
+		Bucket:               aws.String(s3Service.Bucket),
+		Key:                  aws.String(path.Join(s3Service.Prefix, key)),
+		Body:                 body,
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	}
+	if s3Service.KmsKeyId != "" {
+		params.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		params.SSEKMSKeyId = aws.String(s3Service.KmsKeyId)
+	}
+	_, err := s3Service.Service.PutObject(context.TODO(), params)
+	if err != nil {
+		return wrapError("s3:PutObject", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package awshelper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+const (
+	portForwardingDocument = "AWS-StartPortForwardingSession"
+)
+
+type iFSsmService interface {
+	StartSession(ctx context.Context, params *ssm.StartSessionInput, optFns ...func(*ssm.Options)) (*ssm.StartSessionOutput, error)
+}
+
+type SsmService struct {
+	Service iFSsmService
+}
+
+type PortForwardingParams struct {
+	Target       string
+	DocumentName string
+	Parameters   map[string][]string
+}
+
+func (ssmService *SsmService) SetSsmClient(cfg aws.Config) {
+	ssmService.Service = ssm.NewFromConfig(cfg)
+}
+
+func EcsTarget(cluster string, task string, runtimeId string) string {
+	return fmt.Sprintf("ecs:%s_%s_%s", cluster, task, runtimeId)
+}
+
+func (ssmService *SsmService) StartPortForwarding(target string, remotePort string, localPort string) (*ssm.StartSessionOutput, PortForwardingParams, error) {
+	params := PortForwardingParams{
+		Target:       target,
+		DocumentName: portForwardingDocument,
+		Parameters: map[string][]string{
+			"portNumber":      {remotePort},
+			"localPortNumber": {localPort},
+		},
+	}
+	resp, err := ssmService.Service.StartSession(context.TODO(), &ssm.StartSessionInput{
+		Target:       aws.String(params.Target),
+		DocumentName: aws.String(params.DocumentName),
+		Parameters:   params.Parameters,
+	})
+	if err != nil {
+		return nil, params, wrapError("ssm:StartSession", err)
+	}
+	return resp, params, nil
+}
@@ -0,0 +1,38 @@
+package awshelper
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+type iFStsService interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+type StsService struct {
+	Service iFStsService
+}
+
+type Identity struct {
+	Account string
+	Arn     string
+	UserId  string
+}
+
+func (stsService *StsService) SetStsClient(cfg aws.Config) {
+	stsService.Service = sts.NewFromConfig(cfg)
+}
+
+func (stsService *StsService) GetCallerIdentity() (Identity, error) {
+	resp, err := stsService.Service.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return Identity{}, wrapError("sts:GetCallerIdentity", err)
+	}
+	return Identity{
+		Account: aws.ToString(resp.Account),
+		Arn:     aws.ToString(resp.Arn),
+		UserId:  aws.ToString(resp.UserId),
+	}, nil
+}
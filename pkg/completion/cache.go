@@ -0,0 +1,76 @@
+package completion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// タブ補完のたびに AWS API を呼ばないよう、取得した候補を短時間だけ保存する
+const DefaultTTL = 30 * time.Second
+
+type entry struct {
+	Values []string  `json:"values"`
+	Time   time.Time `json:"time"`
+}
+
+type Cache struct {
+	Path string
+	TTL  time.Duration
+}
+
+func NewCache(path string, ttl time.Duration) Cache {
+	return Cache{Path: path, TTL: ttl}
+}
+
+func (cache *Cache) Get(key string) ([]string, bool) {
+	entries, err := cache.load()
+	if err != nil {
+		return nil, false
+	}
+	e, ok := entries[key]
+	if !ok || time.Since(e.Time) >= cache.TTL {
+		return nil, false
+	}
+	return e.Values, true
+}
+
+// 期限切れの候補はこのタイミングで削除する
+func (cache *Cache) Set(key string, values []string) error {
+	entries, err := cache.load()
+	if err != nil {
+		entries = map[string]entry{}
+	}
+	for k, e := range entries {
+		if time.Since(e.Time) >= cache.TTL {
+			delete(entries, k)
+		}
+	}
+	entries[key] = entry{Values: values, Time: time.Now()}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cache.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := cache.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cache.Path)
+}
+
+func (cache *Cache) load() (map[string]entry, error) {
+	entries := map[string]entry{}
+	data, err := os.ReadFile(cache.Path)
+	if err != nil {
+		return entries, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return entries, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package completion_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/completion"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "completion.json")
+	cache := completion.NewCache(path, time.Minute)
+
+	if _, ok := cache.Get("clusters"); ok {
+		t.Fatal("保存していない候補が取得されています。")
+	}
+	if err := cache.Set("clusters", []string{"dev", "prod"}); err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	got, ok := cache.Get("clusters")
+	if !ok || !reflect.DeepEqual(got, []string{"dev", "prod"}) {
+		t.Errorf("保存した候補が取得できません：%v", got)
+	}
+
+	expired := completion.NewCache(path, 0)
+	if _, ok := expired.Get("clusters"); ok {
+		t.Error("期限切れの候補が取得されています。")
+	}
+}
+
+func TestScript(t *testing.T) {
+	cases := []struct {
+		name    string
+		shell   string
+		wantErr bool
+	}{
+		{name: "正常パターン:bash", shell: "bash"},
+		{name: "正常パターン:zsh", shell: "zsh"},
+		{name: "正常パターン:fish", shell: "fish"},
+		{name: "異常パターン:未対応のシェル", shell: "tcsh", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			script, err := completion.Script(c.shell)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("エラーの有無が期待値と異なります：%v", err)
+			}
+			if !c.wantErr && script == "" {
+				t.Error("補完スクリプトが空です。")
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	got := completion.Filter([]string{"api", "app", "worker"}, "ap")
+	if !reflect.DeepEqual(got, []string{"api", "app"}) {
+		t.Errorf("絞り込みの結果が期待値と異なります：%v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// 候補は fexec __complete <入力中の単語まで> で取得する
+	Command = "__complete"
+
+	bashScript = `_fexec() {
+    local IFS=$'\n'
+    COMPREPLY=($(fexec __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _fexec fexec
+`
+	zshScript = `#compdef fexec
+_fexec() {
+    local -a candidates
+    candidates=("${(@f)$(fexec __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -n "${candidates[1]}" ]]; then
+        compadd -- "${candidates[@]}"
+    else
+        _files
+    fi
+}
+compdef _fexec fexec
+`
+	fishScript = `function __fexec_complete
+    set -l args (commandline -opc)[2..-1] (commandline -ct)
+    fexec __complete $args 2>/dev/null
+end
+complete -c fexec -a '(__fexec_complete)'
+`
+)
+
+func Script(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashScript, nil
+	case "zsh":
+		return zshScript, nil
+	case "fish":
+		return fishScript, nil
+	}
+	return "", fmt.Errorf("unsupported shell: %s", shell)
+}
+
+func Filter(candidates []string, prefix string) []string {
+	var filtered []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/gajirou/fexec/pkg/policy"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	targetsKey = "targets"
+)
+
+type Target struct {
+	Profile   string `yaml:"profile,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+	Cluster   string `yaml:"cluster,omitempty"`
+	Service   string `yaml:"service,omitempty"`
+	Container string `yaml:"container,omitempty"`
+	Command   string `yaml:"command,omitempty"`
+	Pick      string `yaml:"pick,omitempty"`
+}
+
+type S3 struct {
+	Bucket   string `yaml:"bucket,omitempty"`
+	Prefix   string `yaml:"prefix,omitempty"`
+	KmsKeyId string `yaml:"kms_key_id,omitempty"`
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+type Audit struct {
+	Log       string `yaml:"log,omitempty"`
+	RecordDir string `yaml:"record_dir,omitempty"`
+	S3        S3     `yaml:"s3,omitempty"`
+}
+
+type Config struct {
+	Profile       string   `yaml:"profile,omitempty"`
+	Region        string   `yaml:"region,omitempty"`
+	Command       string   `yaml:"command,omitempty"`
+	PageSize      int      `yaml:"page_size,omitempty"`
+	MaxCount      int      `yaml:"max_count,omitempty"`
+	Color         string   `yaml:"color,omitempty"`
+	Lang          string   `yaml:"lang,omitempty"`
+	Sidecars      []string `yaml:"sidecars,omitempty"`
+	Audit         Audit    `yaml:"audit,omitempty"`
+	policy.Policy `yaml:",inline"`
+	Targets       map[string]Target `yaml:"targets,omitempty"`
+}
+
+func Load(file string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// 既存のコメントや他の設定を残すため、targets 配下のみを書き換える
+func SaveTarget(file string, name string, target Target) error {
+	return updateTargets(file, func(targets *yaml.Node) error {
+		var value yaml.Node
+		if err := value.Encode(target); err != nil {
+			return err
+		}
+		if i := findKey(targets, name); i >= 0 {
+			targets.Content[i+1] = &value
+			return nil
+		}
+		targets.Content = append(targets.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &value)
+		return nil
+	})
+}
+
+func RemoveTarget(file string, name string) (bool, error) {
+	removed := false
+	err := updateTargets(file, func(targets *yaml.Node) error {
+		if i := findKey(targets, name); i >= 0 {
+			targets.Content = append(targets.Content[:i], targets.Content[i+2:]...)
+			removed = true
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func updateTargets(file string, update func(targets *yaml.Node) error) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	i := findKey(root, targetsKey)
+	if i < 0 {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: targetsKey}, &yaml.Node{Kind: yaml.MappingNode})
+		i = len(root.Content) - 2
+	}
+	if err := update(root.Content[i+1]); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(file, buf.Bytes(), 0o600)
+}
+
+func findKey(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,215 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/config"
+)
+
+func TestSaveTarget(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	data := `# fexec の設定
+targets:
+  # 本番 API
+  api-prod:
+    profile: prod
+    cluster: prod
+    service: api
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := config.SaveTarget(file, "worker-stg", config.Target{Profile: "stg", Cluster: "stg", Service: "worker", Container: "app"})
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	cfg, err := config.Load(file)
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if len(cfg.Targets) != 2 || cfg.Targets["worker-stg"].Container != "app" || cfg.Targets["api-prod"].Service != "api" {
+		t.Errorf("ブックマークの内容が期待値と異なります：%+v", cfg.Targets)
+	}
+	saved, _ := os.ReadFile(file)
+	if !strings.Contains(string(saved), "# 本番 API") {
+		t.Error("既存のコメントが保持されていません。")
+	}
+
+	removed, err := config.RemoveTarget(file, "api-prod")
+	if err != nil || !removed {
+		t.Fatal("ブックマークが削除されていません。")
+	}
+	cfg, _ = config.Load(file)
+	if _, ok := cfg.Targets["api-prod"]; ok {
+		t.Error("ブックマークが削除されていません。")
+	}
+}
+
+func TestSaveTargetNewFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fexec", "config.yaml")
+	if err := config.SaveTarget(file, "api", config.Target{Cluster: "prod", Service: "api"}); err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	cfg, err := config.Load(file)
+	if err != nil || cfg.Targets["api"].Cluster != "prod" {
+		t.Error("ブックマークが保存されていません。")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "config.yaml")
+	projectFile := filepath.Join(dir, "project", config.ProjectFile)
+	user := `profile: dev
+command: /bin/bash
+protected:
+  - cluster: main
+targets:
+  api:
+    cluster: main
+`
+	project := `region: us-east-1
+command: echo pwned
+reason_pattern: .*
+audit:
+  log: /dev/null
+  s3:
+    bucket: attacker
+    endpoint: http://attacker.example.com
+sidecars:
+  - fluent-bit
+protected:
+  - cluster: prod-*
+targets:
+  api:
+    cluster: other
+    command: echo pwned
+  worker:
+    cluster: main
+    command: echo pwned
+`
+	if err := os.WriteFile(userFile, []byte(user), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(projectFile), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(projectFile, []byte(project), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"FEXEC_REGION": "eu-west-1", "FEXEC_PAGE_SIZE": "20", "FEXEC_COMMAND": "echo pwned", "FEXEC_AUDIT_LOG": "/dev/null"}
+
+	defaults := config.Config{Profile: "default", Command: "/bin/sh", PageSize: 7, MaxCount: 50, Sidecars: []string{"envoy"}, Audit: config.Audit{Log: "/var/log/fexec.log"}}
+	cfg, err := config.Resolve(defaults, []string{userFile}, projectFile, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if cfg.Profile != "dev" || cfg.Command != "/bin/bash" || cfg.Region != "eu-west-1" || cfg.PageSize != 20 || cfg.MaxCount != 50 {
+		t.Errorf("設定の優先順位が期待値と異なります：%+v", cfg)
+	}
+	if len(cfg.Sidecars) != 1 || cfg.Sidecars[0] != "fluent-bit" {
+		t.Errorf("サイドカーの一覧が置き換えられていません：%v", cfg.Sidecars)
+	}
+	if len(cfg.Protected) != 2 || len(cfg.Targets) != 2 {
+		t.Errorf("保護対象またはブックマークが結合されていません：%+v", cfg)
+	}
+	if cfg.Targets["api"].Cluster != "main" || cfg.Targets["worker"].Command != "" {
+		t.Errorf("プロジェクト設定のブックマークがユーザー設定を上書きしています：%+v", cfg.Targets)
+	}
+	if cfg.Audit.Log != "/var/log/fexec.log" || cfg.Audit.S3.Bucket != "" || cfg.Audit.S3.Endpoint != "" || cfg.ReasonPattern != ".*" {
+		t.Errorf("プロジェクト設定・環境変数で監査の設定が変更されています：%+v", cfg)
+	}
+
+	user = "reason_pattern: ^OPS-[0-9]+$\n"
+	if err := os.WriteFile(userFile, []byte(user), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env = map[string]string{"FEXEC_S3_BUCKET": "audit", "FEXEC_REASON_PATTERN": ".*"}
+	cfg, err = config.Resolve(defaults, []string{userFile}, projectFile, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if cfg.ReasonPattern != "^OPS-[0-9]+$" || cfg.Audit.S3.Bucket != "audit" {
+		t.Errorf("接続理由の形式が緩められているか、環境変数の S3 設定が反映されていません：%+v", cfg)
+	}
+
+	// ポリシーファイルの接続理由の形式は、プロジェクト設定や FEXEC_CONFIG・FEXEC_POLICY で指定したファイルで緩められない
+	policyFile := filepath.Join(dir, "policy.yaml")
+	envFile := filepath.Join(dir, "env.yaml")
+	if err := os.WriteFile(userFile, []byte("profile: dev\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(policyFile, []byte("reason_pattern: ^INC-[0-9]+$\nprotected:\n  - cluster: prod-*\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(envFile, []byte("command: echo pwned\nreason_pattern: .*\naudit:\n  log: /dev/null\nprotected:\n  - cluster: stg\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env = map[string]string{"FEXEC_CONFIG": envFile, "FEXEC_POLICY": envFile}
+	cfg, err = config.Resolve(defaults, []string{policyFile, userFile}, projectFile, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if cfg.ReasonPattern != "^INC-[0-9]+$" || cfg.Command != "/bin/sh" || cfg.Audit.Log != "/var/log/fexec.log" {
+		t.Errorf("環境変数で指定したファイルで監査の設定が変更されています：%+v", cfg)
+	}
+	if len(cfg.Protected) != 4 {
+		t.Errorf("保護対象が結合されていません：%+v", cfg.Protected)
+	}
+
+	env["FEXEC_MAX_COUNT"] = "500"
+	if _, err := config.Resolve(defaults, nil, "", func(key string) string { return env[key] }); err == nil {
+		t.Error("範囲外の max_count がエラーになっていません。")
+	}
+	env["FEXEC_MAX_COUNT"] = "many"
+	if _, err := config.Resolve(defaults, nil, "", func(key string) string { return env[key] }); err == nil {
+		t.Error("数値以外の max_count がエラーになっていません。")
+	}
+}
+
+func TestFindProjectFile(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, config.ProjectFile)
+	if err := os.WriteFile(file, []byte("region: us-east-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.FindProjectFile(sub); got != file {
+		t.Errorf("プロジェクト設定のパスが期待値と異なります：%s", got)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     string
+		env      map[string]string
+		terminal bool
+		want     bool
+	}{
+		{name: "正常パターン:auto（端末）", mode: config.ColorAuto, terminal: true, want: true},
+		{name: "正常パターン:auto（パイプ）", mode: config.ColorAuto, terminal: false, want: false},
+		{name: "正常パターン:auto（NO_COLOR）", mode: config.ColorAuto, env: map[string]string{"NO_COLOR": "1"}, terminal: true, want: false},
+		{name: "正常パターン:auto（TERM=dumb）", mode: config.ColorAuto, env: map[string]string{"TERM": "dumb"}, terminal: true, want: false},
+		{name: "正常パターン:always（パイプ・NO_COLOR）", mode: config.ColorAlways, env: map[string]string{"NO_COLOR": "1"}, terminal: false, want: true},
+		{name: "正常パターン:never（端末）", mode: config.ColorNever, terminal: true, want: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			getenv := func(key string) string { return c.env[key] }
+			if got := config.UseColor(c.mode, getenv, c.terminal); got != c.want {
+				t.Errorf("色付けの判定が期待値と異なります：%v", got)
+			}
+		})
+	}
+	if err := config.ValidateColor("rainbow"); err == nil {
+		t.Error("不正な値がエラーになっていません。")
+	}
+}
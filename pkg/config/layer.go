@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gajirou/fexec/pkg/policy"
+)
+
+const (
+	ProjectFile = ".fexec.yaml"
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+	maxPageSize = 100
+	maxMaxCount = 100
+)
+
+// 組み込みの初期値、ユーザー設定（ポリシーファイルを含む）、プロジェクト設定、環境変数の順に後勝ちで重ね合わせる
+// プロジェクト設定と環境変数では、監査・接続理由・S3・コマンドの設定を緩めたり上書きしたりできない
+// FEXEC_CONFIG・FEXEC_POLICY で指定したファイルも環境変数として扱う
+func Resolve(defaults Config, userFiles []string, projectFile string, getenv func(string) string) (Config, error) {
+	cfg := defaults
+	for _, file := range userFiles {
+		user, err := Load(file)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", file, err)
+		}
+		cfg = Merge(cfg, user)
+	}
+	trusted := cfg
+	if projectFile != "" {
+		project, err := Load(projectFile)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", projectFile, err)
+		}
+		cfg = Merge(cfg, restrictProject(trusted, project))
+	}
+	for _, key := range []string{"FEXEC_POLICY", "FEXEC_CONFIG"} {
+		file := getenv(key)
+		if file == "" {
+			continue
+		}
+		env, err := Load(file)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", file, err)
+		}
+		cfg = Merge(cfg, restrictEnv(trusted, env))
+	}
+	env, err := FromEnv(getenv)
+	if err != nil {
+		return cfg, err
+	}
+	cfg = Merge(cfg, restrictEnv(trusted, env))
+	return cfg, cfg.Validate()
+}
+
+// リポジトリに置かれたファイルからは監査とコマンドを変更させず、接続理由の形式は未設定の場合のみ補う
+func restrictProject(trusted Config, project Config) Config {
+	project.Command = ""
+	project.Audit = Audit{}
+	if trusted.ReasonPattern != "" {
+		project.ReasonPattern = ""
+	}
+	project.Targets = restrictTargets(trusted, project.Targets)
+	return project
+}
+
+// ユーザー設定と同名のブックマークは上書きさせず、コマンドは反映しない
+func restrictTargets(trusted Config, targets map[string]Target) map[string]Target {
+	restricted := map[string]Target{}
+	for name, target := range targets {
+		if _, ok := trusted.Targets[name]; ok {
+			continue
+		}
+		target.Command = ""
+		restricted[name] = target
+	}
+	return restricted
+}
+
+// 環境変数では監査ログの記録先とコマンドを変更させず、記録・S3・接続理由の形式はユーザー設定にない場合のみ補う
+func restrictEnv(trusted Config, env Config) Config {
+	env.Command = ""
+	env.Audit.Log = ""
+	if trusted.Audit.RecordDir != "" {
+		env.Audit.RecordDir = ""
+	}
+	if trusted.Audit.S3.Bucket != "" {
+		env.Audit.S3 = S3{}
+	}
+	if trusted.ReasonPattern != "" {
+		env.ReasonPattern = ""
+	}
+	env.Targets = restrictTargets(trusted, env.Targets)
+	return env
+}
+
+// 保護対象は上位の設定で解除できないよう追加のみとする
+func Merge(base Config, over Config) Config {
+	merged := base
+	mergeString(&merged.Profile, over.Profile)
+	mergeString(&merged.Region, over.Region)
+	mergeString(&merged.Command, over.Command)
+	mergeString(&merged.Color, over.Color)
+	mergeString(&merged.Lang, over.Lang)
+	mergeString(&merged.Audit.Log, over.Audit.Log)
+	mergeString(&merged.Audit.RecordDir, over.Audit.RecordDir)
+	mergeString(&merged.Audit.S3.Bucket, over.Audit.S3.Bucket)
+	mergeString(&merged.Audit.S3.Prefix, over.Audit.S3.Prefix)
+	mergeString(&merged.Audit.S3.KmsKeyId, over.Audit.S3.KmsKeyId)
+	mergeString(&merged.Audit.S3.Endpoint, over.Audit.S3.Endpoint)
+	mergeString(&merged.ReasonPattern, over.ReasonPattern)
+	if over.PageSize != 0 {
+		merged.PageSize = over.PageSize
+	}
+	if over.MaxCount != 0 {
+		merged.MaxCount = over.MaxCount
+	}
+	if len(over.Sidecars) > 0 {
+		merged.Sidecars = over.Sidecars
+	}
+	merged.Protected = append(append([]policy.Rule{}, base.Protected...), over.Protected...)
+	if len(base.Targets) > 0 || len(over.Targets) > 0 {
+		merged.Targets = map[string]Target{}
+		maps.Copy(merged.Targets, base.Targets)
+		maps.Copy(merged.Targets, over.Targets)
+	}
+	return merged
+}
+
+func FromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		Profile: getenv("FEXEC_PROFILE"),
+		Region:  getenv("FEXEC_REGION"),
+		Color:   getenv("FEXEC_COLOR"),
+		Lang:    getenv("FEXEC_LANG"),
+		Audit: Audit{
+			RecordDir: getenv("FEXEC_RECORD_DIR"),
+			S3: S3{
+				Bucket:   getenv("FEXEC_S3_BUCKET"),
+				Prefix:   getenv("FEXEC_S3_PREFIX"),
+				KmsKeyId: getenv("FEXEC_S3_KMS_KEY_ID"),
+				Endpoint: getenv("FEXEC_S3_ENDPOINT"),
+			},
+		},
+	}
+	cfg.ReasonPattern = getenv("FEXEC_REASON_PATTERN")
+	if sidecars := getenv("FEXEC_SIDECARS"); sidecars != "" {
+		cfg.Sidecars = strings.Split(sidecars, ",")
+	}
+	var err error
+	if cfg.PageSize, err = envInt(getenv, "FEXEC_PAGE_SIZE"); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxCount, err = envInt(getenv, "FEXEC_MAX_COUNT"); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (cfg Config) Validate() error {
+	if cfg.PageSize < 0 || cfg.PageSize > maxPageSize {
+		return fmt.Errorf("page_size must be between 1 and %d (0 uses the default): %d", maxPageSize, cfg.PageSize)
+	}
+	if cfg.MaxCount < 0 || cfg.MaxCount > maxMaxCount {
+		return fmt.Errorf("max_count must be between 1 and %d (0 uses the default): %d", maxMaxCount, cfg.MaxCount)
+	}
+	if err := ValidateColor(cfg.Color); err != nil {
+		return err
+	}
+	if _, err := regexp.Compile(cfg.Pattern()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidateColor(mode string) error {
+	switch mode {
+	case "", ColorAuto, ColorAlways, ColorNever:
+		return nil
+	}
+	return fmt.Errorf("color must be %s, %s or %s: %s", ColorAuto, ColorAlways, ColorNever, mode)
+}
+
+// auto の場合は NO_COLOR が設定されている、TERM が dumb、または出力先が端末でなければ色を付けない
+func UseColor(mode string, getenv func(string) string, terminal bool) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return getenv("NO_COLOR") == "" && getenv("TERM") != "dumb" && terminal
+}
+
+// カレントディレクトリから親ディレクトリをたどって .fexec.yaml を探す
+func FindProjectFile(dir string) string {
+	for {
+		file := filepath.Join(dir, ProjectFile)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func mergeString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func envInt(getenv func(string) string, key string) (int, error) {
+	value := getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	maxEntries = 50
+)
+
+type Entry struct {
+	Profile   string    `json:"profile"`
+	Region    string    `json:"region"`
+	Cluster   string    `json:"cluster"`
+	Service   string    `json:"service"`
+	Task      string    `json:"task"`
+	Container string    `json:"container"`
+	Time      time.Time `json:"time"`
+}
+
+type Store struct {
+	Path string
+}
+
+func NewStore(path string) Store {
+	return Store{Path: path}
+}
+
+func (store *Store) List() (entries []Entry, err error) {
+	data, err := os.ReadFile(store.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (store *Store) Last() (Entry, bool, error) {
+	entries, err := store.List()
+	if err != nil || len(entries) <= 0 {
+		return Entry{}, false, err
+	}
+	return entries[0], true, nil
+}
+
+// 同じ接続先は最新の 1 件のみ残し、新しい順に保存する
+func (store *Store) Add(entry Entry) error {
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	merged := []Entry{entry}
+	for _, e := range entries {
+		if e.sameTarget(entry) {
+			continue
+		}
+		merged = append(merged, e)
+	}
+	if len(merged) > maxEntries {
+		merged = merged[:maxEntries]
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(store.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := store.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, store.Path)
+}
+
+func (e Entry) sameTarget(other Entry) bool {
+	return e.Profile == other.Profile &&
+		e.Region == other.Region &&
+		e.Cluster == other.Cluster &&
+		e.Service == other.Service &&
+		e.Container == other.Container
+}
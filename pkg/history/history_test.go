@@ -0,0 +1,44 @@
+package history_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/history"
+)
+
+func TestAdd(t *testing.T) {
+	store := history.NewStore(filepath.Join(t.TempDir(), "history.json"))
+
+	if _, ok, err := store.Last(); ok || err != nil {
+		t.Fatal("履歴が存在しない場合の戻り値が不正です。")
+	}
+
+	entries := []history.Entry{
+		{Profile: "default", Region: "ap-northeast-1", Cluster: "prod", Service: "api", Task: "task1", Container: "app"},
+		{Profile: "default", Region: "ap-northeast-1", Cluster: "prod", Service: "worker", Task: "task2", Container: "app"},
+		{Profile: "default", Region: "ap-northeast-1", Cluster: "prod", Service: "api", Task: "task3", Container: "app"},
+	}
+	for i, e := range entries {
+		e.Time = time.Date(2024, 4, 1, i, 0, 0, 0, time.UTC)
+		if err := store.Add(e); err != nil {
+			t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+		}
+	}
+
+	got, err := store.List()
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if len(got) != 2 {
+		t.Fatalf("同じ接続先の履歴がまとめられていません：%d", len(got))
+	}
+	last, ok, err := store.Last()
+	if !ok || err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if last.Service != "api" || last.Task != "task3" {
+		t.Errorf("最新の履歴が期待値と異なります：%+v", last)
+	}
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Table = "table"
+	JSON  = "json"
+	YAML  = "yaml"
+)
+
+type Printer struct {
+	Format   string
+	Template string
+}
+
+func (printer Printer) Validate() error {
+	switch printer.Format {
+	case Table, JSON, YAML:
+	default:
+		return fmt.Errorf("unsupported output format: %s", printer.Format)
+	}
+	if printer.Template != "" {
+		if _, err := newTemplate(printer.Template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// テンプレートが指定された場合は形式より優先し、一覧の場合は要素ごとに出力する
+func (printer Printer) Print(w io.Writer, data interface{}, header []string, rows [][]string) error {
+	if printer.Template != "" {
+		return printTemplate(w, printer.Template, data)
+	}
+	switch printer.Format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(emptySlice(data))
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(emptySlice(data)); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case Table:
+		return printTable(w, header, rows)
+	}
+	return fmt.Errorf("unsupported output format: %s", printer.Format)
+}
+
+// 単一のリソースを表形式で出力する場合は項目名と値を一行ずつ並べる
+func (printer Printer) PrintFields(w io.Writer, data interface{}) error {
+	if printer.Template != "" || printer.Format != Table {
+		return printer.Print(w, data, nil, nil)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	v := reflect.Indirect(reflect.ValueOf(data))
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		fmt.Fprintf(tw, "%s:\t%s\n", name, formatValue(v.Field(i).Interface()))
+	}
+	return tw.Flush()
+}
+
+func formatValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format(time.DateTime)
+	}
+	return fmt.Sprint(value)
+}
+
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func printTemplate(w io.Writer, text string, data interface{}) error {
+	tmpl, err := newTemplate(text)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		if err := tmpl.Execute(w, data); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := tmpl.Execute(w, v.Index(i).Interface()); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTemplate(text string) (*template.Template, error) {
+	return template.New("output").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"join": strings.Join,
+	}).Parse(text)
+}
+
+// 空の一覧を null ではなく [] として出力する
+func emptySlice(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	return data
+}
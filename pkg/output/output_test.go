@@ -0,0 +1,133 @@
+package output_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/output"
+)
+
+type row struct {
+	Name   string `json:"name" yaml:"name"`
+	Status string `json:"status" yaml:"status"`
+}
+
+func TestPrint(t *testing.T) {
+	data := []row{{Name: "api", Status: "ACTIVE"}, {Name: "worker", Status: "DRAINING"}}
+	header := []string{"NAME", "STATUS"}
+	rows := [][]string{{"api", "ACTIVE"}, {"worker", "DRAINING"}}
+	cases := []struct {
+		name    string
+		printer output.Printer
+		data    []row
+		want    string
+	}{
+		{
+			name:    "正常パターン:表形式",
+			printer: output.Printer{Format: output.Table},
+			data:    data,
+			want:    "NAME    STATUS\napi     ACTIVE\nworker  DRAINING\n",
+		},
+		{
+			name:    "正常パターン:JSON",
+			printer: output.Printer{Format: output.JSON},
+			data:    data,
+			want:    "[\n  {\n    \"name\": \"api\",\n    \"status\": \"ACTIVE\"\n  },\n  {\n    \"name\": \"worker\",\n    \"status\": \"DRAINING\"\n  }\n]\n",
+		},
+		{
+			name:    "正常パターン:JSON（空の一覧）",
+			printer: output.Printer{Format: output.JSON},
+			data:    nil,
+			want:    "[]\n",
+		},
+		{
+			name:    "正常パターン:YAML",
+			printer: output.Printer{Format: output.YAML},
+			data:    data,
+			want:    "- name: api\n  status: ACTIVE\n- name: worker\n  status: DRAINING\n",
+		},
+		{
+			name:    "正常パターン:テンプレート",
+			printer: output.Printer{Format: output.Table, Template: "{{.Name}}={{.Status}}"},
+			data:    data,
+			want:    "api=ACTIVE\nworker=DRAINING\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.printer.Validate(); err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			var out bytes.Buffer
+			if err := c.printer.Print(&out, c.data, header, rows); err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			if out.String() != c.want {
+				t.Errorf("出力が期待値と異なります：%q", out.String())
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (output.Printer{Format: "xml"}).Validate(); err == nil {
+		t.Error("未対応の形式がエラーになっていません。")
+	}
+	if err := (output.Printer{Format: output.Table, Template: "{{.Name"}).Validate(); err == nil {
+		t.Error("不正なテンプレートがエラーになっていません。")
+	}
+}
+
+func TestPrintFields(t *testing.T) {
+	data := row{Name: "api", Status: "ACTIVE"}
+	cases := []struct {
+		name    string
+		printer output.Printer
+		want    string
+	}{
+		{
+			name:    "正常パターン:表形式",
+			printer: output.Printer{Format: output.Table},
+			want:    "name:    api\nstatus:  ACTIVE\n",
+		},
+		{
+			name:    "正常パターン:JSON",
+			printer: output.Printer{Format: output.JSON},
+			want:    "{\n  \"name\": \"api\",\n  \"status\": \"ACTIVE\"\n}\n",
+		},
+		{
+			name:    "正常パターン:テンプレート",
+			printer: output.Printer{Format: output.Table, Template: "{{.Name}}"},
+			want:    "api\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := c.printer.PrintFields(&out, data); err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			if out.String() != c.want {
+				t.Errorf("出力が期待値と異なります：%q", out.String())
+			}
+		})
+	}
+}
+
+func TestTagWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	a := output.NewTagWriter(&buf, &mu, "aaa")
+	b := output.NewTagWriter(&buf, &mu, "bbb")
+	a.Write([]byte("0.10 0.2"))
+	b.Write([]byte("1.00\r\n"))
+	a.Write([]byte("0 0.30\r\nlast"))
+	if err := a.Flush(); err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	want := "bbb\t1.00\naaa\t0.10 0.20 0.30\naaa\tlast\n"
+	if buf.String() != want {
+		t.Errorf("出力が期待値と異なります：%q", buf.String())
+	}
+}
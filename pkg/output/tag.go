@@ -0,0 +1,52 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// 並列に実行したコマンドの出力を行単位で先頭に目印を付けて書き込む
+type TagWriter struct {
+	w       io.Writer
+	mu      *sync.Mutex
+	tag     string
+	pending []byte
+}
+
+func NewTagWriter(w io.Writer, mu *sync.Mutex, tag string) *TagWriter {
+	return &TagWriter{w: w, mu: mu, tag: tag}
+}
+
+func (tw *TagWriter) Write(p []byte) (int, error) {
+	tw.pending = append(tw.pending, p...)
+	for {
+		i := bytes.IndexByte(tw.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(tw.pending[:i])
+		tw.pending = tw.pending[i+1:]
+		if err := tw.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// 改行で終わらない最後の行を書き込む
+func (tw *TagWriter) Flush() error {
+	if len(tw.pending) == 0 {
+		return nil
+	}
+	line := string(tw.pending)
+	tw.pending = nil
+	return tw.writeLine(line)
+}
+
+func (tw *TagWriter) writeLine(line string) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	_, err := io.WriteString(tw.w, tw.tag+"\t"+strings.TrimRight(line, "\r")+"\n")
+	return err
+}
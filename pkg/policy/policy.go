@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultReasonPattern = `\S+`
+)
+
+var (
+	ErrReasonRequired = errors.New("reason is required for protected target")
+	ErrReasonMismatch = errors.New("reason does not match the required pattern")
+)
+
+type Rule struct {
+	Cluster string `yaml:"cluster,omitempty"`
+	Service string `yaml:"service,omitempty"`
+}
+
+type Policy struct {
+	Protected     []Rule `yaml:"protected,omitempty"`
+	ReasonPattern string `yaml:"reason_pattern,omitempty"`
+}
+
+func Load(file string) (Policy, error) {
+	var p Policy
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return p, err
+	}
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	if _, err := p.reasonRegexp(); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func (p Policy) IsProtected(cluster string, service string) bool {
+	for _, rule := range p.Protected {
+		if match(rule.Cluster, cluster) && match(rule.Service, service) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) ValidateReason(reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	re, err := p.reasonRegexp()
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(reason) {
+		return ErrReasonMismatch
+	}
+	return nil
+}
+
+func (p Policy) Pattern() string {
+	if p.ReasonPattern == "" {
+		return defaultReasonPattern
+	}
+	return p.ReasonPattern
+}
+
+func (p Policy) reasonRegexp() (*regexp.Regexp, error) {
+	return regexp.Compile(p.Pattern())
+}
+
+func match(pattern string, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
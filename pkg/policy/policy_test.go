@@ -0,0 +1,95 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/policy"
+)
+
+func TestPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	data := `protected:
+  - cluster: prod-*
+  - cluster: stg
+    service: payment
+reason_pattern: 'OPS-\d+'
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pol, err := policy.Load(file)
+	if err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+
+	cases := []struct {
+		name      string
+		cluster   string
+		service   string
+		reason    string
+		protected bool
+		errFlag   bool
+	}{
+		{
+			name:      "正常パターン:クラスター一致",
+			cluster:   "prod-api",
+			service:   "api",
+			reason:    "OPS-123",
+			protected: true,
+			errFlag:   false,
+		},
+		{
+			name:      "正常パターン:保護対象外",
+			cluster:   "stg",
+			service:   "api",
+			reason:    "",
+			protected: false,
+			errFlag:   true,
+		},
+		{
+			name:      "異常パターン:理由未入力",
+			cluster:   "stg",
+			service:   "payment",
+			reason:    "",
+			protected: true,
+			errFlag:   true,
+		},
+		{
+			name:      "異常パターン:理由の形式不一致",
+			cluster:   "prod-api",
+			service:   "api",
+			reason:    "調査のため",
+			protected: true,
+			errFlag:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if pol.IsProtected(c.cluster, c.service) != c.protected {
+				t.Error("保護対象の判定が期待値と異なります。")
+			}
+			err := pol.ValidateReason(c.reason)
+			if c.errFlag {
+				if err == nil {
+					t.Error("関数の戻り値にエラーが含まれていません。")
+				}
+			} else {
+				if err != nil {
+					t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+		})
+	}
+}
+
+func TestLoadNotExist(t *testing.T) {
+	pol, err := policy.Load(filepath.Join(t.TempDir(), "policy.yaml"))
+	if err != nil {
+		t.Error("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if pol.IsProtected("prod", "api") {
+		t.Error("ポリシーファイルが存在しない場合に保護対象と判定されています。")
+	}
+}
@@ -0,0 +1,97 @@
+package probe
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// command にこの値を指定すると、コンテナで利用できるシェルを判別して起動する
+	Auto   = "auto"
+	marker = "fexec-shell="
+	// 優先度の高い順に探し、見つかったシェルのパスを目印付きで出力する
+	Script      = `for s in bash zsh ash sh; do p=$(command -v $s) && [ -x "$p" ] && echo ` + marker + `$p && break; done`
+	defaultTerm = "xterm-256color"
+)
+
+// 判別の際にスクリプトを実行するシェル。/bin/sh がないイメージでは busybox を試す
+var Wrappers = []string{"/bin/sh", "/busybox/sh"}
+
+type Result struct {
+	Wrapper string `json:"wrapper"`
+	Shell   string `json:"shell"`
+}
+
+func Parse(out string) (string, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		if shell, ok := strings.CutPrefix(strings.TrimSpace(line), marker); ok && shell != "" {
+			return shell, true
+		}
+	}
+	return "", false
+}
+
+// 手元の TERM を引き継いでログインシェルとして起動する
+func LoginCommand(shell string, term string) string {
+	if term == "" {
+		term = defaultTerm
+	}
+	return "TERM='" + strings.ReplaceAll(term, "'", "") + "' " + shell + " -l"
+}
+
+// タスク定義のリビジョンは変更されないため、判別結果は期限なしで保存する
+type Store struct {
+	Path string
+}
+
+func NewStore(path string) Store {
+	return Store{Path: path}
+}
+
+func Key(taskDefinition string, container string) string {
+	return taskDefinition + "#" + container
+}
+
+func (store *Store) Get(key string) (Result, bool) {
+	results, err := store.load()
+	if err != nil {
+		return Result{}, false
+	}
+	result, ok := results[key]
+	return result, ok
+}
+
+func (store *Store) Set(key string, result Result) error {
+	results, err := store.load()
+	if err != nil {
+		results = map[string]Result{}
+	}
+	results[key] = result
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(store.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := store.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, store.Path)
+}
+
+func (store *Store) load() (map[string]Result, error) {
+	results := map[string]Result{}
+	data, err := os.ReadFile(store.Path)
+	if err != nil {
+		return results, err
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return results, err
+	}
+	return results, nil
+}
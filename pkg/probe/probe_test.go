@@ -0,0 +1,64 @@
+package probe_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/probe"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name  string
+		out   string
+		want  string
+		found bool
+	}{
+		{name: "正常パターン:端末の改行", out: "\r\nfexec-shell=/bin/bash\r\n", want: "/bin/bash", found: true},
+		{name: "正常パターン:前後の出力", out: "motd\nfexec-shell=/bin/ash\n", want: "/bin/ash", found: true},
+		{name: "異常パターン:シェルが見つからない", out: "sh: not found\r\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, found := probe.Parse(c.out)
+			if got != c.want || found != c.found {
+				t.Errorf("判別結果が期待値と異なります：%s %v", got, found)
+			}
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	out, err := exec.Command("/bin/sh", "-c", probe.Script).Output()
+	if err != nil {
+		t.Fatal("判別スクリプトの実行に失敗しました。")
+	}
+	if _, ok := probe.Parse(string(out)); !ok {
+		t.Errorf("シェルが判別できません：%q", out)
+	}
+}
+
+func TestLoginCommand(t *testing.T) {
+	if got := probe.LoginCommand("/bin/bash", "screen-256color"); got != "TERM='screen-256color' /bin/bash -l" {
+		t.Errorf("起動コマンドが期待値と異なります：%s", got)
+	}
+	if got := probe.LoginCommand("/bin/ash", ""); got != "TERM='xterm-256color' /bin/ash -l" {
+		t.Errorf("TERM 未設定時の起動コマンドが期待値と異なります：%s", got)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := probe.NewStore(filepath.Join(t.TempDir(), "shells.json"))
+	key := probe.Key("arn:aws:ecs:ap-northeast-1:123456789012:task-definition/api:3", "app")
+	if _, ok := store.Get(key); ok {
+		t.Fatal("保存していない判別結果が取得されています。")
+	}
+	want := probe.Result{Wrapper: "/bin/sh", Shell: "/bin/bash"}
+	if err := store.Set(key, want); err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	if got, ok := store.Get(key); !ok || got != want {
+		t.Errorf("保存した判別結果が取得できません：%+v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	castVersion   = 2
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	pending []byte
+	now     func() time.Time
+}
+
+func Create(path string, title string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	width, height, err := term.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		width, height = defaultWidth, defaultHeight
+	}
+	r, err := NewRecorder(file, width, height, title)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func NewRecorder(w io.Writer, width int, height int, title string) (*Recorder, error) {
+	return newRecorder(w, width, height, title, time.Now)
+}
+
+func newRecorder(w io.Writer, width int, height int, title string, now func() time.Time) (*Recorder, error) {
+	r := &Recorder{w: w, start: now(), now: now}
+	h := header{
+		Version:   castVersion,
+		Width:     width,
+		Height:    height,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env: map[string]string{
+			"SHELL": os.Getenv("SHELL"),
+			"TERM":  os.Getenv("TERM"),
+		},
+	}
+	line, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.pending, p...)
+	cut := completeLength(data)
+	r.pending = append([]byte{}, data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+	if err := r.event("o", string(data[:cut])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (r *Recorder) Resize(width int, height int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.event("r", fmt.Sprintf("%dx%d", width, height))
+}
+
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		return nil
+	}
+	data := string(r.pending)
+	r.pending = nil
+	return r.event("o", data)
+}
+
+func (r *Recorder) Close() error {
+	err := r.Flush()
+	if closer, ok := r.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (r *Recorder) event(code string, data string) error {
+	elapsed := r.now().Sub(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(r.w, "%s\n", line)
+	return err
+}
+
+// 末尾のマルチバイト文字が途中で切れている場合は次回の書き込みに持ち越す
+func completeLength(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(p)
+}
@@ -0,0 +1,75 @@
+package recorder_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/recorder"
+)
+
+func TestRecorder(t *testing.T) {
+	cases := []struct {
+		name   string
+		writes []string
+		resize bool
+		want   []string
+	}{
+		{
+			name:   "正常パターン:出力イベント",
+			writes: []string{"hello\r\n", "world"},
+			want:   []string{"o:hello\r\n", "o:world"},
+		},
+		{
+			name:   "正常パターン:マルチバイト文字の分割",
+			writes: []string{"あ"[:2], "あ"[2:] + "い"},
+			want:   []string{"o:あい"},
+		},
+		{
+			name:   "正常パターン:リサイズイベント",
+			writes: []string{"a"},
+			resize: true,
+			want:   []string{"o:a", "r:120x40"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			rec, err := recorder.NewRecorder(&buf, 80, 24, "title")
+			if err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			for _, w := range c.writes {
+				if _, err := rec.Write([]byte(w)); err != nil {
+					t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+			if c.resize {
+				rec.Resize(120, 40)
+			}
+			rec.Close()
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			var header map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+				t.Fatal("ヘッダーが JSON ではありません。")
+			}
+			if header["version"] != float64(2) || header["width"] != float64(80) || header["height"] != float64(24) {
+				t.Errorf("ヘッダーの内容が不正です：%s", lines[0])
+			}
+
+			var got []string
+			for _, line := range lines[1:] {
+				var event []interface{}
+				if err := json.Unmarshal([]byte(line), &event); err != nil {
+					t.Fatal("イベントが JSON ではありません。")
+				}
+				got = append(got, event[1].(string)+":"+event[2].(string))
+			}
+			if strings.Join(got, "|") != strings.Join(c.want, "|") {
+				t.Errorf("イベントが期待値と異なります：%q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"io"
+	"strings"
+)
+
+const (
+	keyEnter     = '\r'
+	keyNewline   = '\n'
+	keyBackspace = 0x7f
+	keyCtrlH     = 0x08
+	keyCtrlC     = 0x03
+	keyEsc       = 0x1b
+)
+
+type Escape struct {
+	Char       byte
+	Out        io.Writer
+	Help       string
+	Info       func() string
+	Command    func(line string) string
+	Disconnect func()
+	midLine    bool
+	pending    bool
+	inCommand  bool
+	line       []byte
+}
+
+func (escape *Escape) Filter(p []byte) []byte {
+	var out []byte
+	for _, b := range p {
+		switch {
+		case escape.inCommand:
+			escape.readCommand(b)
+		case escape.pending:
+			escape.pending = false
+			out = append(out, escape.handle(b)...)
+		case b == escape.Char && !escape.midLine:
+			escape.pending = true
+		default:
+			out = append(out, b)
+			escape.midLine = b != keyEnter && b != keyNewline
+		}
+	}
+	return out
+}
+
+func (escape *Escape) handle(b byte) []byte {
+	switch b {
+	case '.':
+		escape.print("\n")
+		if escape.Disconnect != nil {
+			escape.Disconnect()
+		}
+	case '?':
+		escape.print("\n" + escape.Help)
+	case '#':
+		if escape.Info != nil {
+			escape.print("\n" + escape.Info())
+		}
+	case 'C':
+		escape.inCommand = true
+		escape.line = nil
+		escape.print("\nfexec> ")
+	case escape.Char:
+		escape.midLine = true
+		return []byte{escape.Char}
+	default:
+		escape.midLine = b != keyEnter && b != keyNewline
+		return []byte{escape.Char, b}
+	}
+	return nil
+}
+
+func (escape *Escape) readCommand(b byte) {
+	switch b {
+	case keyEnter, keyNewline:
+		escape.inCommand = false
+		escape.print("\n")
+		line := strings.TrimSpace(string(escape.line))
+		if line != "" && escape.Command != nil {
+			escape.print(escape.Command(line))
+		}
+	case keyCtrlC, keyEsc:
+		escape.inCommand = false
+		escape.print("\n")
+	case keyBackspace, keyCtrlH:
+		if len(escape.line) > 0 {
+			escape.line = escape.line[:len(escape.line)-1]
+			escape.print("\b \b")
+		}
+	default:
+		escape.line = append(escape.line, b)
+		escape.Out.Write([]byte{b})
+	}
+}
+
+// raw モードの端末では改行だけでは行頭に戻らないため CRLF に変換する
+func (escape *Escape) print(s string) {
+	escape.Out.Write([]byte(strings.ReplaceAll(s, "\n", "\r\n")))
+}
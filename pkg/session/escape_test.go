@@ -0,0 +1,90 @@
+package session_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/session"
+)
+
+func TestEscapeFilter(t *testing.T) {
+	cases := []struct {
+		name       string
+		input      []string
+		want       string
+		disconnect bool
+		command    string
+		output     string
+	}{
+		{
+			name:  "正常パターン:エスケープなし",
+			input: []string{"ls\r"},
+			want:  "ls\r",
+		},
+		{
+			name:  "正常パターン:行の途中のエスケープ文字はそのまま送信",
+			input: []string{"echo ~.\r"},
+			want:  "echo ~.\r",
+		},
+		{
+			name:       "正常パターン:切断",
+			input:      []string{"~", "."},
+			want:       "",
+			disconnect: true,
+		},
+		{
+			name:  "正常パターン:エスケープ文字の送信",
+			input: []string{"\r~~"},
+			want:  "\r~",
+		},
+		{
+			name:  "正常パターン:未定義のシーケンス",
+			input: []string{"~a"},
+			want:  "~a",
+		},
+		{
+			name:   "正常パターン:ヘルプ",
+			input:  []string{"~?"},
+			want:   "",
+			output: "help",
+		},
+		{
+			name:    "正常パターン:コマンドプロンプト",
+			input:   []string{"~C", "-L 8080:8\x7f80\r", "pwd\r"},
+			want:    "pwd\r",
+			command: "-L 8080:80",
+			output:  "fexec> ",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var command string
+			disconnected := false
+			escape := &session.Escape{
+				Char:       '~',
+				Out:        &out,
+				Help:       "help",
+				Command:    func(line string) string { command = line; return "" },
+				Disconnect: func() { disconnected = true },
+			}
+			var got []byte
+			for _, in := range c.input {
+				got = append(got, escape.Filter([]byte(in))...)
+			}
+			if string(got) != c.want {
+				t.Errorf("送信内容が期待値と異なります：%q", got)
+			}
+			if disconnected != c.disconnect {
+				t.Error("切断の判定が期待値と異なります。")
+			}
+			if command != c.command {
+				t.Errorf("コマンドが期待値と異なります：%q", command)
+			}
+			if !strings.Contains(out.String(), c.output) {
+				t.Errorf("出力が期待値と異なります：%q", out.String())
+			}
+		})
+	}
+}
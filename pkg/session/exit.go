@@ -0,0 +1,113 @@
+package session
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	exitPrefix    = "\x1b]7771;fexec-exit-"
+	exitSuffix    = '\a'
+	maxExitDigits = 3
+)
+
+// 終了コードの目印に含めるセッションごとの乱数
+// 接続先の出力に含まれる同じ形式のシーケンスで終了コードを偽装されないようにする
+type ExitMarker string
+
+func NewExitMarker() ExitMarker {
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	return ExitMarker(hex.EncodeToString(nonce))
+}
+
+// 端末には無視される OSC シーケンスとして終了コードを出力させる
+func (marker ExitMarker) Script() string {
+	return fmt.Sprintf(`; printf '\033]7771;fexec-exit-%s=%%d\007' $?`, marker)
+}
+
+// コマンドレットは $LASTEXITCODE を設定しないため、成否から終了コードを決める
+func (marker ExitMarker) PowerShellScript() string {
+	return fmt.Sprintf(`; $fexecOk = $?; $fexecExit = if ($null -ne $LASTEXITCODE) { $LASTEXITCODE -band 255 } elseif ($fexecOk) { 0 } else { 1 }; [Console]::Write("$([char]27)]7771;fexec-exit-%s=$fexecExit$([char]7)")`, marker)
+}
+
+type ExitFilter struct {
+	w       io.Writer
+	prefix  []byte
+	pending []byte
+	code    int
+	found   bool
+}
+
+func NewExitFilter(w io.Writer, marker ExitMarker) *ExitFilter {
+	return &ExitFilter{w: w, prefix: []byte(exitPrefix + string(marker) + "=")}
+}
+
+func (filter *ExitFilter) Code() (int, bool) {
+	return filter.code, filter.found
+}
+
+func (filter *ExitFilter) Write(p []byte) (int, error) {
+	data := append(filter.pending, p...)
+	filter.pending = nil
+	var out []byte
+	for {
+		i := bytes.Index(data, filter.prefix)
+		if i < 0 {
+			keep := filter.partialPrefix(data)
+			out = append(out, data[:len(data)-keep]...)
+			filter.pending = append([]byte{}, data[len(data)-keep:]...)
+			break
+		}
+		out = append(out, data[:i]...)
+		rest := data[i+len(filter.prefix):]
+		end := bytes.IndexByte(rest, exitSuffix)
+		if end < 0 {
+			if len(rest) > maxExitDigits {
+				out = append(out, data[i:i+len(filter.prefix)]...)
+				data = rest
+				continue
+			}
+			filter.pending = append([]byte{}, data[i:]...)
+			break
+		}
+		code, err := strconv.Atoi(string(rest[:end]))
+		if err != nil || end > maxExitDigits {
+			out = append(out, data[i:i+len(filter.prefix)]...)
+			data = rest
+			continue
+		}
+		filter.code = code
+		filter.found = true
+		data = rest[end+1:]
+	}
+	if len(out) > 0 {
+		if _, err := filter.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (filter *ExitFilter) Flush() error {
+	if len(filter.pending) <= 0 {
+		return nil
+	}
+	_, err := filter.w.Write(filter.pending)
+	filter.pending = nil
+	return err
+}
+
+// 末尾が終了コードの開始部分と一致する場合は次の書き込みまで保留する
+func (filter *ExitFilter) partialPrefix(data []byte) int {
+	for n := min(len(data), len(filter.prefix)-1); n > 0; n-- {
+		if bytes.Equal(data[len(data)-n:], filter.prefix[:n]) {
+			return n
+		}
+	}
+	return 0
+}
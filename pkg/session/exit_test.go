@@ -0,0 +1,96 @@
+package session_test
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/session"
+)
+
+func TestExitFilter(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []string
+		want  string
+		code  int
+		found bool
+	}{
+		{
+			name:  "正常パターン:終了コードなし",
+			input: []string{"$ ls\r\n", "\x1b[1mfile\x1b[0m\r\n"},
+			want:  "$ ls\r\n\x1b[1mfile\x1b[0m\r\n",
+		},
+		{
+			name:  "正常パターン:終了コードを取り除く",
+			input: []string{"exit\r\n\x1b]7771;fexec-exit-0123abcd=3\a"},
+			want:  "exit\r\n",
+			code:  3,
+			found: true,
+		},
+		{
+			name:  "正常パターン:分割された終了コード",
+			input: []string{"bye\x1b]77", "71;fexec-ex", "it-0123abcd=12", "7\a\r\n"},
+			want:  "bye\r\n",
+			code:  127,
+			found: true,
+		},
+		{
+			name:  "正常パターン:終了コード以外の OSC シーケンス",
+			input: []string{"\x1b]0;title\a", "\x1b]7771;fexec-exit-0123abcd=abc\a"},
+			want:  "\x1b]0;title\a\x1b]7771;fexec-exit-0123abcd=abc\a",
+		},
+		{
+			name:  "異常パターン:目印の異なる終了コードは出力に残す",
+			input: []string{"\x1b]7771;fexec-exit-ffffffff=0\a", "\x1b]7771;fexec-exit=0\a", "\x1b]7771;fexec-exit-0123abcd=2\a"},
+			want:  "\x1b]7771;fexec-exit-ffffffff=0\a\x1b]7771;fexec-exit=0\a",
+			code:  2,
+			found: true,
+		},
+		{
+			name:  "正常パターン:途中で終わった場合は保留分を出力",
+			input: []string{"done\x1b]7771;fex"},
+			want:  "done\x1b]7771;fex",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			filter := session.NewExitFilter(&out, "0123abcd")
+			for _, in := range c.input {
+				if _, err := filter.Write([]byte(in)); err != nil {
+					t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+				}
+			}
+			filter.Flush()
+			if out.String() != c.want {
+				t.Errorf("出力が期待値と異なります：%q", out.String())
+			}
+			code, found := filter.Code()
+			if code != c.code || found != c.found {
+				t.Errorf("終了コードが期待値と異なります：%d %v", code, found)
+			}
+		})
+	}
+}
+
+func TestExitMarker(t *testing.T) {
+	marker := session.NewExitMarker()
+	if marker == "" || marker == session.NewExitMarker() {
+		t.Fatalf("目印が乱数になっていません：%s", marker)
+	}
+	var out bytes.Buffer
+	filter := session.NewExitFilter(&out, marker)
+	cmd := exec.Command("/bin/sh", "-c", "echo done; (exit 5)"+marker.Script())
+	cmd.Stdout = filter
+	if err := cmd.Run(); err != nil {
+		t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+	}
+	filter.Flush()
+	if out.String() != "done\n" {
+		t.Errorf("出力が期待値と異なります：%q", out.String())
+	}
+	if code, found := filter.Code(); code != 5 || !found {
+		t.Errorf("終了コードが期待値と異なります：%d %v", code, found)
+	}
+}
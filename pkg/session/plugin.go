@@ -0,0 +1,45 @@
+package session
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ECS Exec に対応した session-manager-plugin の最小バージョン
+const MinPluginVersion = "1.2.54.0"
+
+func PluginVersion(plugin string) (string, error) {
+	out, err := exec.Command(plugin, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func SupportsEcsExec(version string) bool {
+	return CompareVersions(version, MinPluginVersion) >= 0
+}
+
+// ドット区切りの数値を先頭から比較し、数値でない部分は 0 として扱う
+func CompareVersions(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		x, y := versionPart(as, i), versionPart(bs, i)
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
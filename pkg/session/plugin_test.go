@@ -0,0 +1,37 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/session"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{name: "正常パターン:同じバージョン", a: "1.2.54.0", b: "1.2.54.0", want: 0},
+		{name: "正常パターン:新しいバージョン", a: "1.2.553.0", b: "1.2.54.0", want: 1},
+		{name: "正常パターン:古いバージョン", a: "1.1.61.0", b: "1.2.54.0", want: -1},
+		{name: "正常パターン:桁数が異なる", a: "1.2.54", b: "1.2.54.0", want: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := session.CompareVersions(c.a, c.b); got != c.want {
+				t.Errorf("比較結果が期待値と異なります：%d", got)
+			}
+		})
+	}
+}
+
+func TestSupportsEcsExec(t *testing.T) {
+	if session.SupportsEcsExec("1.1.61.0") {
+		t.Error("古いバージョンが対応と判定されています。")
+	}
+	if !session.SupportsEcsExec("1.2.677.0") {
+		t.Error("新しいバージョンが非対応と判定されています。")
+	}
+}
@@ -0,0 +1,12 @@
+package session
+
+// 異常切断の場合のみ再接続する
+// 利用者が切断した場合や、リモートのシェルが終了コードを返して終了した場合は再接続しない
+func NeedsReconnect(runErr error, terminated bool, remoteExited bool) bool {
+	return runErr != nil && !terminated && !remoteExited
+}
+
+func (session *Session) NeedsReconnect(runErr error) bool {
+	_, remoteExited := session.RemoteExitCode()
+	return NeedsReconnect(runErr, session.terminated, remoteExited)
+}
@@ -0,0 +1,37 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/session"
+)
+
+func TestNeedsReconnect(t *testing.T) {
+	disconnected := errors.New("exit status 255")
+	cases := []struct {
+		name         string
+		runErr       error
+		terminated   bool
+		remoteExited bool
+		want         bool
+	}{
+		{name: "正常パターン:異常切断", runErr: disconnected, want: true},
+		{name: "正常パターン:正常終了", runErr: nil},
+		{name: "正常パターン:利用者による切断", runErr: disconnected, terminated: true},
+		{name: "正常パターン:リモートのシェルが終了", runErr: disconnected, remoteExited: true},
+		{name: "正常パターン:リモートのシェルが終了し利用者も切断", runErr: disconnected, terminated: true, remoteExited: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := session.NeedsReconnect(c.runErr, c.terminated, c.remoteExited); got != c.want {
+				t.Errorf("再接続の判定が期待値と異なります：%v", got)
+			}
+		})
+	}
+
+	ses := &session.Session{}
+	if !ses.NeedsReconnect(disconnected) || ses.NeedsReconnect(nil) {
+		t.Error("未実行のセッションの再接続の判定が期待値と異なります。")
+	}
+}
@@ -0,0 +1,282 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/gajirou/fexec/pkg/recorder"
+	"golang.org/x/term"
+)
+
+const (
+	drainTimeout = time.Second
+	killTimeout  = 5 * time.Second
+	bufferSize   = 32 * 1024
+)
+
+var (
+	inputOnce sync.Once
+	input     chan []byte
+)
+
+type Session struct {
+	Plugin     string
+	Args       []string
+	Title      string
+	Recorder   *recorder.Recorder
+	Escape     *Escape
+	ExitMarker ExitMarker
+	Stdin      io.Reader
+	Stdout     io.Writer
+	exit       *ExitFilter
+	terminated bool
+	disconnect chan struct{}
+	once       sync.Once
+}
+
+func (session *Session) Terminated() bool {
+	return session.terminated
+}
+
+func (session *Session) RemoteExitCode() (int, bool) {
+	if session.exit == nil {
+		return 0, false
+	}
+	return session.exit.Code()
+}
+
+func (session *Session) Disconnect() {
+	session.once.Do(func() {
+		close(session.disconnect)
+	})
+}
+
+func (session *Session) Run() error {
+	session.terminated = false
+	session.disconnect = make(chan struct{})
+	session.once = sync.Once{}
+	session.exit = nil
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	cmd := exec.Command(session.Plugin, session.Args...)
+	fd := int(os.Stdin.Fd())
+	if session.Stdin != nil || session.Stdout != nil || (!term.IsTerminal(fd) && session.Recorder == nil) {
+		return session.runWithPipe(cmd, sigs)
+	}
+
+	if term.IsTerminal(fd) {
+		state, err := term.GetState(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+		// 標準出力をリダイレクトしている場合は、タイトルの制御シーケンスが出力に混ざらないよう変更しない
+		if session.Title != "" && term.IsTerminal(int(os.Stdout.Fd())) {
+			pushTitle(session.Title)
+			defer popTitle()
+		}
+	}
+	return session.runWithPty(cmd, sigs)
+}
+
+func (session *Session) runWithPipe(cmd *exec.Cmd, sigs chan os.Signal) error {
+	cmd.Stderr = os.Stderr
+	var out io.Writer = os.Stdout
+	if session.Stdout != nil {
+		out = session.Stdout
+	}
+	cmd.Stdout = session.output(out)
+	if session.Stdin != nil {
+		// 標準入力を閉じるとリモートのコマンドの完了前にセッションが終了するため、パイプは閉じない
+		pipe, err := cmd.StdinPipe()
+		if err != nil {
+			return err
+		}
+		go io.Copy(pipe, session.Stdin)
+	} else {
+		cmd.Stdin = os.Stdin
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err := session.wait(cmd, sigs)
+	session.flush()
+	return err
+}
+
+func (session *Session) runWithPty(cmd *exec.Cmd, sigs chan os.Signal) error {
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		return err
+	}
+	defer ptmx.Close()
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+	go func() {
+		for range winch {
+			if err := pty.InheritSize(os.Stdin, ptmx); err != nil {
+				continue
+			}
+			if width, height, err := term.GetSize(int(os.Stdin.Fd())); err == nil && session.Recorder != nil {
+				session.Recorder.Resize(width, height)
+			}
+		}
+	}()
+	pty.InheritSize(os.Stdin, ptmx)
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		if _, err := term.MakeRaw(int(os.Stdin.Fd())); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return err
+		}
+	}
+
+	exited := make(chan struct{})
+	defer close(exited)
+	go session.copyInput(ptmx, exited)
+	var out io.Writer = os.Stdout
+	if session.Recorder != nil {
+		out = io.MultiWriter(os.Stdout, session.Recorder)
+	}
+	out = session.output(out)
+	done := make(chan struct{})
+	go func() {
+		io.Copy(out, ptmx)
+		close(done)
+	}()
+
+	err = session.wait(cmd, sigs)
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		ptmx.Close()
+		<-done
+	}
+	session.flush()
+	if session.Recorder != nil {
+		session.Recorder.Flush()
+	}
+	return err
+}
+
+func (session *Session) output(w io.Writer) io.Writer {
+	if session.ExitMarker == "" {
+		return w
+	}
+	session.exit = NewExitFilter(w, session.ExitMarker)
+	return session.exit
+}
+
+func (session *Session) flush() {
+	if session.exit != nil {
+		session.exit.Flush()
+	}
+}
+
+func (session *Session) copyInput(dst io.Writer, exited chan struct{}) {
+	escape := session.Escape
+	if escape != nil && term.IsTerminal(int(os.Stdin.Fd())) {
+		escape.Out = os.Stdout
+		escape.Disconnect = session.Disconnect
+	} else {
+		escape = nil
+	}
+	in := stdin()
+	for {
+		select {
+		case p, ok := <-in:
+			if !ok {
+				return
+			}
+			if escape != nil {
+				p = escape.Filter(p)
+			}
+			if _, err := dst.Write(p); err != nil {
+				return
+			}
+		case <-exited:
+			return
+		}
+	}
+}
+
+// 再接続時に前回のセッションが標準入力を読み捨てないよう、読み込みは一つのゴルーチンで行う
+func stdin() <-chan []byte {
+	inputOnce.Do(func() {
+		input = make(chan []byte)
+		go func() {
+			for {
+				buf := make([]byte, bufferSize)
+				n, err := os.Stdin.Read(buf)
+				if n > 0 {
+					input <- buf[:n]
+				}
+				if err != nil {
+					close(input)
+					return
+				}
+			}
+		}()
+	})
+	return input
+}
+
+// SIGINT は端末経由で子プロセスに届くため、SIGTERM と SIGHUP のみ転送する
+func (session *Session) wait(cmd *exec.Cmd, sigs chan os.Signal) error {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	for {
+		var sig os.Signal = syscall.SIGTERM
+		select {
+		case err := <-exited:
+			return err
+		case sig = <-sigs:
+			if sig == os.Interrupt {
+				continue
+			}
+		case <-session.disconnect:
+		}
+		session.terminated = true
+		cmd.Process.Signal(sig)
+		select {
+		case err := <-exited:
+			return err
+		case <-time.After(killTimeout):
+			cmd.Process.Kill()
+			return <-exited
+		}
+	}
+}
+
+func pushTitle(title string) {
+	fmt.Fprintf(os.Stdout, "\x1b[22;0t\x1b]0;%s\x07", title)
+}
+
+func popTitle() {
+	fmt.Fprint(os.Stdout, "\x1b[23;0t")
+}
+
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,152 @@
+package sink
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	tmpPrefix    = "."
+	minBackoff   = time.Second
+	maxBackoff   = 30 * time.Second
+	pollInterval = 100 * time.Millisecond
+	// 呼び出し元のアカウントを取得できなかった場合のキーの先頭
+	UnknownAccount = "unknown"
+)
+
+type Uploader interface {
+	Upload(key string, body io.Reader) error
+}
+
+type Sink struct {
+	Uploader Uploader
+	SpoolDir string
+	wake     chan struct{}
+}
+
+func NewSink(uploader Uploader, spoolDir string) *Sink {
+	return &Sink{Uploader: uploader, SpoolDir: spoolDir, wake: make(chan struct{}, 1)}
+}
+
+func Key(account string, cluster string, date time.Time, sessionId string, ext string) string {
+	if account == "" {
+		account = UnknownAccount
+	}
+	return path.Join(account, cluster, date.UTC().Format("2006-01-02"), sessionId+ext)
+}
+
+func (sink *Sink) Start() {
+	go sink.run()
+}
+
+func (sink *Sink) EnqueueData(key string, data []byte) error {
+	if err := os.MkdirAll(sink.SpoolDir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(sink.SpoolDir, tmpPrefix+"spool-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return sink.commit(tmp.Name(), key)
+}
+
+func (sink *Sink) EnqueueFile(key string, src string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return sink.EnqueueData(key, data)
+}
+
+func (sink *Sink) Pending() []string {
+	entries, err := os.ReadDir(sink.SpoolDir)
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), tmpPrefix) {
+			continue
+		}
+		if key, err := url.PathUnescape(e.Name()); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (sink *Sink) Wait(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if len(sink.Pending()) == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func (sink *Sink) commit(tmp string, key string) error {
+	if err := os.Rename(tmp, filepath.Join(sink.SpoolDir, url.PathEscape(key))); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	select {
+	case sink.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (sink *Sink) run() {
+	backoff := minBackoff
+	for {
+		failed := false
+		for _, key := range sink.Pending() {
+			if err := sink.upload(key); err != nil {
+				failed = true
+			}
+		}
+		wait := maxBackoff
+		if failed {
+			wait = backoff
+			backoff = min(backoff*2, maxBackoff)
+		} else {
+			backoff = minBackoff
+		}
+		select {
+		case <-sink.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (sink *Sink) upload(key string) error {
+	spoolFile := filepath.Join(sink.SpoolDir, url.PathEscape(key))
+	file, err := os.Open(spoolFile)
+	if err != nil {
+		return err
+	}
+	err = sink.Uploader.Upload(key, file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(spoolFile)
+}
@@ -0,0 +1,106 @@
+package sink_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/sink"
+)
+
+type mockUploader struct {
+	mu       sync.Mutex
+	failures int
+	uploaded map[string]string
+}
+
+func (m *mockUploader) Upload(key string, body io.Reader) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("error")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.uploaded[key] = string(data)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	cases := []struct {
+		name     string
+		failures int
+		timeout  time.Duration
+		drained  bool
+	}{
+		{
+			name:     "正常パターン",
+			failures: 0,
+			timeout:  time.Second,
+			drained:  true,
+		},
+		{
+			name:     "正常パターン:リトライ後に成功",
+			failures: 1,
+			timeout:  5 * time.Second,
+			drained:  true,
+		},
+		{
+			name:     "異常パターン:アップロード失敗時はスプールに残る",
+			failures: 1000,
+			timeout:  200 * time.Millisecond,
+			drained:  false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			uploader := &mockUploader{failures: c.failures, uploaded: map[string]string{}}
+			s3Sink := sink.NewSink(uploader, t.TempDir())
+			key := sink.Key("111111111111", "cluster", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), "session", ".json")
+			if key != "111111111111/cluster/2024-04-01/session.json" {
+				t.Errorf("キーが期待値と異なります：%s", key)
+			}
+			if err := s3Sink.EnqueueData(key, []byte("data")); err != nil {
+				t.Fatal("関数の戻り値に予期せぬエラーが含まれています。")
+			}
+			s3Sink.Start()
+
+			if s3Sink.Wait(c.timeout) != c.drained {
+				t.Fatal("アップロード結果が期待値と異なります。")
+			}
+			if c.drained {
+				uploader.mu.Lock()
+				defer uploader.mu.Unlock()
+				if uploader.uploaded[key] != "data" {
+					t.Error("アップロード内容が期待値と異なります。")
+				}
+			} else if len(s3Sink.Pending()) != 1 {
+				t.Error("スプールにファイルが残っていません。")
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	date := time.Date(2024, 4, 1, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	cases := []struct {
+		name    string
+		account string
+		want    string
+	}{
+		{name: "正常パターン", account: "111111111111", want: "111111111111/cluster/2024-04-01/session.json"},
+		{name: "正常パターン:アカウントを取得できない", account: "", want: "unknown/cluster/2024-04-01/session.json"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if key := sink.Key(c.account, "cluster", date, "session", ".json"); key != c.want {
+				t.Errorf("キーが期待値と異なります：%s", key)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+)
+
+type item struct {
+	name    string
+	arn     string
+	summary string
+	details [][2]string
+	raw     interface{}
+}
+
+func clusterItems(clusters []awshelper.ClusterInfo) (items []item) {
+	for _, c := range clusters {
+		items = append(items, item{
+			name:    c.Name,
+			arn:     c.Arn,
+			summary: fmt.Sprintf("%s  services:%d tasks:%d", c.Status, c.ActiveServices, c.RunningTasks),
+			details: [][2]string{
+				{"Name", c.Name},
+				{"ARN", c.Arn},
+				{"Status", c.Status},
+				{"Active services", fmt.Sprint(c.ActiveServices)},
+				{"Running tasks", fmt.Sprint(c.RunningTasks)},
+				{"Pending tasks", fmt.Sprint(c.PendingTasks)},
+			},
+			raw: c,
+		})
+	}
+	return items
+}
+
+func serviceItems(services []awshelper.ServiceInfo) (items []item) {
+	for _, s := range services {
+		items = append(items, item{
+			name:    s.Name,
+			arn:     s.Arn,
+			summary: fmt.Sprintf("%s  %d/%d", s.Status, s.RunningCount, s.DesiredCount),
+			details: [][2]string{
+				{"Name", s.Name},
+				{"ARN", s.Arn},
+				{"Status", s.Status},
+				{"Task definition", s.TaskDefinition},
+				{"Launch type", s.LaunchType},
+				{"Running / Desired", fmt.Sprintf("%d / %d", s.RunningCount, s.DesiredCount)},
+				{"Pending", fmt.Sprint(s.PendingCount)},
+				{"Execute command", fmt.Sprint(s.EnableExecuteCommand)},
+			},
+			raw: s,
+		})
+	}
+	return items
+}
+
+func taskItems(tasks []awshelper.TaskInfo) (items []item) {
+	for _, t := range tasks {
+		items = append(items, item{
+			name:    t.Id,
+			arn:     t.Arn,
+			summary: fmt.Sprintf("%s  %s", t.LastStatus, t.AvailabilityZone),
+			details: [][2]string{
+				{"ID", t.Id},
+				{"ARN", t.Arn},
+				{"Status", t.LastStatus + " / " + t.DesiredStatus},
+				{"Health", t.HealthStatus},
+				{"Task definition", t.TaskDefinitionArn},
+				{"Availability zone", t.AvailabilityZone},
+				{"Private IP", t.PrivateIp},
+				{"Network interface", t.NetworkInterfaceId},
+				{"Launch type", t.LaunchType},
+				{"Platform family", t.PlatformFamily},
+				{"Started at", t.StartedAt.Local().Format(time.DateTime)},
+				{"Execute command", fmt.Sprint(t.EnableExecuteCommand)},
+			},
+			raw: t,
+		})
+	}
+	return items
+}
+
+func containerItems(containers []awshelper.ContainerInfo) (items []item) {
+	for _, c := range containers {
+		items = append(items, item{
+			name:    c.Name,
+			arn:     c.Arn,
+			summary: fmt.Sprintf("%s  %s", c.LastStatus, c.HealthStatus),
+			details: [][2]string{
+				{"Name", c.Name},
+				{"ARN", c.Arn},
+				{"Image", c.Image},
+				{"Status", c.LastStatus},
+				{"Health", c.HealthStatus},
+				{"Runtime ID", c.RuntimeId},
+				{"Exec agent", c.ExecAgent},
+				{"Essential", fmt.Sprint(c.Essential)},
+			},
+			raw: c,
+		})
+	}
+	return items
+}
@@ -0,0 +1,76 @@
+package tui
+
+const (
+	LevelCluster = iota
+	LevelService
+	LevelTask
+	LevelContainer
+)
+
+// 一覧の階層と、各階層で選択した名前・カーソル位置を管理する
+type Navigator struct {
+	level      int
+	path       []string
+	cursor     []int
+	generation int
+}
+
+// 一覧の取得要求
+// 取得中に階層を移動した場合、古い要求の結果は Current で破棄する
+type Request struct {
+	Level      int
+	Generation int
+	Selection  Selection
+}
+
+func NewNavigator() *Navigator {
+	return &Navigator{path: make([]string, LevelContainer+1)}
+}
+
+func (nav *Navigator) Level() int {
+	return nav.level
+}
+
+// 指定の階層に移動し、それより下で選択していた名前を消去する
+func (nav *Navigator) Load(level int) Request {
+	nav.level = level
+	nav.path = append(nav.path[:level], make([]string, LevelContainer+1-level)...)
+	nav.generation++
+	return Request{Level: level, Generation: nav.generation, Selection: nav.Selection()}
+}
+
+func (nav *Navigator) Current(req Request) bool {
+	return req.Generation == nav.generation && req.Level == nav.level
+}
+
+// 選択した名前を記録して次の階層を返す、コンテナを選択した場合は done が true
+func (nav *Navigator) Enter(name string, index int) (next int, done bool) {
+	nav.path[nav.level] = name
+	nav.cursor = append(nav.cursor[:nav.level], index)
+	if nav.level == LevelContainer {
+		return nav.level, true
+	}
+	return nav.level + 1, false
+}
+
+// 一つ上の階層と、その階層で選択していたカーソル位置を返す
+func (nav *Navigator) Back() (level int, cursor int, ok bool) {
+	if nav.level == LevelCluster {
+		return LevelCluster, 0, false
+	}
+	level = nav.level - 1
+	return level, nav.cursor[level], true
+}
+
+func (nav *Navigator) Selection() Selection {
+	return Selection{
+		Cluster:   nav.path[LevelCluster],
+		Service:   nav.path[LevelService],
+		Task:      nav.path[LevelTask],
+		Container: nav.path[LevelContainer],
+	}
+}
+
+func (nav *Navigator) Crumbs() []string {
+	return append([]string(nil), nav.path[:nav.level]...)
+}
@@ -0,0 +1,349 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var (
+	levelLabels = []string{"tui.clusters", "tui.services", "tui.tasks", "tui.containers"}
+)
+
+type Source interface {
+	ListClusterInfos() ([]awshelper.ClusterInfo, error)
+	ListServiceInfos(cluster string) ([]awshelper.ServiceInfo, error)
+	ListTaskInfos(cluster string, service string) ([]awshelper.TaskInfo, error)
+	ListContainerInfos(cluster string, task string) ([]awshelper.ContainerInfo, error)
+	ContainerLogs(cluster string, task string, container string) ([]string, error)
+}
+
+type Selection struct {
+	Cluster   string
+	Service   string
+	Task      string
+	Container string
+}
+
+type Browser struct {
+	Auto     bool
+	Sidecars []string
+	source   Source
+	title    string
+	app      *tview.Application
+	screen   tcell.Screen
+	pages    *tview.Pages
+	header   *tview.TextView
+	list     *tview.List
+	filter   *tview.InputField
+	details  *tview.TextView
+	footer   *tview.TextView
+	nav      *Navigator
+	items    []item
+	shown    []item
+	selected bool
+}
+
+func NewBrowser(source Source, title string) *Browser {
+	return &Browser{source: source, title: title, nav: NewNavigator()}
+}
+
+func (browser *Browser) Run() (Selection, bool, error) {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return Selection{}, false, err
+	}
+	browser.screen = screen
+	browser.app = tview.NewApplication().SetScreen(screen)
+	browser.layout()
+	browser.load(LevelCluster, 0, true)
+	if err := browser.app.Run(); err != nil {
+		return Selection{}, false, err
+	}
+	if !browser.selected {
+		return Selection{}, false, nil
+	}
+	return browser.nav.Selection(), true, nil
+}
+
+func (browser *Browser) layout() {
+	browser.header = tview.NewTextView().SetDynamicColors(true)
+	browser.footer = tview.NewTextView().SetDynamicColors(true)
+	browser.list = tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true)
+	browser.list.SetBorder(true)
+	browser.list.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		browser.showDetails(index)
+	})
+	browser.list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		browser.enter(index)
+	})
+	browser.list.SetInputCapture(browser.listKeys)
+
+	browser.filter = tview.NewInputField().SetLabel(utils.Label("tui.filter"))
+	browser.filter.SetChangedFunc(func(text string) {
+		browser.applyFilter(text)
+	})
+	browser.filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			browser.filter.SetText("")
+		}
+		browser.app.SetFocus(browser.list)
+	})
+
+	browser.details = tview.NewTextView().SetDynamicColors(true)
+	browser.details.SetBorder(true).SetTitle(" " + utils.Label("tui.details") + " ")
+
+	left := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(browser.list, 0, 1, true).
+		AddItem(browser.filter, 1, 0, false)
+	body := tview.NewFlex().
+		AddItem(left, 0, 1, true).
+		AddItem(browser.details, 0, 1, false)
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(browser.header, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(browser.footer, 1, 0, false)
+
+	browser.pages = tview.NewPages().AddPage("main", root, true, true)
+	browser.app.SetRoot(browser.pages, true)
+	browser.setFooter(utils.Label("tui.keys"))
+}
+
+func (browser *Browser) listKeys(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape, tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyLeft:
+		browser.back()
+		return nil
+	case tcell.KeyRight:
+		browser.enter(browser.list.GetCurrentItem())
+		return nil
+	}
+	switch event.Rune() {
+	case 'q':
+		browser.app.Stop()
+	case '/':
+		browser.app.SetFocus(browser.filter)
+	case 'r':
+		browser.load(browser.nav.Level(), browser.list.GetCurrentItem(), false)
+	case 'e':
+		browser.enter(browser.list.GetCurrentItem())
+	case 'l':
+		browser.showLogs()
+	case 'd':
+		browser.describe()
+	case 'y':
+		browser.copyArn()
+	default:
+		return event
+	}
+	return nil
+}
+
+// 次の一覧に進む場合のみ自動で選択し、戻った場合や再読み込みでは選択しない
+// 取得中に階層を移動した場合は、古い取得結果を破棄する
+func (browser *Browser) load(level int, cursor int, advance bool) {
+	req := browser.nav.Load(level)
+	browser.items = nil
+	browser.shown = nil
+	browser.list.Clear()
+	browser.list.SetTitle(" " + utils.Label(levelLabels[level]) + " ")
+	browser.details.SetText(utils.Label("tui.loading"))
+	browser.setHeader()
+	go func() {
+		items, err := browser.fetch(req)
+		browser.app.QueueUpdateDraw(func() {
+			if !browser.nav.Current(req) {
+				return
+			}
+			if err != nil {
+				browser.details.SetText("[red]" + tview.Escape(err.Error()))
+				return
+			}
+			browser.items = items
+			browser.applyFilter(browser.filter.GetText())
+			if advance && browser.Auto {
+				if level < LevelContainer && len(items) == 1 {
+					browser.enter(0)
+					return
+				}
+				if index, ok := browser.appContainer(); ok {
+					cursor = index
+				}
+			}
+			if cursor < browser.list.GetItemCount() {
+				browser.list.SetCurrentItem(cursor)
+			}
+		})
+	}()
+}
+
+func (browser *Browser) fetch(req Request) ([]item, error) {
+	sel := req.Selection
+	switch req.Level {
+	case LevelCluster:
+		clusters, err := browser.source.ListClusterInfos()
+		return clusterItems(clusters), err
+	case LevelService:
+		services, err := browser.source.ListServiceInfos(sel.Cluster)
+		return serviceItems(services), err
+	case LevelTask:
+		tasks, err := browser.source.ListTaskInfos(sel.Cluster, sel.Service)
+		return taskItems(tasks), err
+	default:
+		containers, err := browser.source.ListContainerInfos(sel.Cluster, sel.Task)
+		return containerItems(containers), err
+	}
+}
+
+func (browser *Browser) applyFilter(text string) {
+	browser.shown = nil
+	browser.list.Clear()
+	for _, it := range browser.items {
+		if text != "" && !strings.Contains(strings.ToLower(it.name), strings.ToLower(text)) {
+			continue
+		}
+		browser.shown = append(browser.shown, it)
+		browser.list.AddItem(fmt.Sprintf("%s  [gray]%s", tview.Escape(it.name), tview.Escape(it.summary)), "", 0, nil)
+	}
+	browser.showDetails(browser.list.GetCurrentItem())
+}
+
+func (browser *Browser) showDetails(index int) {
+	it, ok := browser.current(index)
+	if !ok {
+		browser.details.SetText("")
+		return
+	}
+	var b strings.Builder
+	for _, d := range it.details {
+		fmt.Fprintf(&b, "[yellow]%s[-]\n  %s\n", tview.Escape(d[0]), tview.Escape(d[1]))
+	}
+	browser.details.SetText(b.String()).ScrollToBeginning()
+}
+
+func (browser *Browser) enter(index int) {
+	it, ok := browser.current(index)
+	if !ok {
+		return
+	}
+	next, done := browser.nav.Enter(it.name, index)
+	if done {
+		browser.selected = true
+		browser.app.Stop()
+		return
+	}
+	browser.filter.SetText("")
+	browser.load(next, 0, true)
+}
+
+func (browser *Browser) back() {
+	level, cursor, ok := browser.nav.Back()
+	if !ok {
+		return
+	}
+	browser.filter.SetText("")
+	browser.load(level, cursor, false)
+}
+
+// コンテナの一覧ではサイドカーを除いたアプリケーションのコンテナにカーソルを合わせる
+func (browser *Browser) appContainer() (int, bool) {
+	if browser.nav.Level() != LevelContainer {
+		return 0, false
+	}
+	var containers []awshelper.ContainerInfo
+	for _, it := range browser.shown {
+		if c, ok := it.raw.(awshelper.ContainerInfo); ok {
+			containers = append(containers, c)
+		}
+	}
+	name, ok := awshelper.AppContainer(containers, browser.Sidecars)
+	if !ok {
+		return 0, false
+	}
+	for i, it := range browser.shown {
+		if it.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (browser *Browser) showLogs() {
+	it, ok := browser.current(browser.list.GetCurrentItem())
+	if !ok || browser.nav.Level() != LevelContainer {
+		browser.setFooter(utils.Label("tui.logs_unavailable"))
+		return
+	}
+	sel := browser.nav.Selection()
+	view := browser.popup(utils.Label("tui.logs")+": "+it.name, utils.Label("tui.loading"))
+	go func() {
+		lines, err := browser.source.ContainerLogs(sel.Cluster, sel.Task, it.name)
+		text := strings.Join(lines, "\n")
+		if err != nil {
+			text = err.Error()
+		}
+		browser.app.QueueUpdateDraw(func() {
+			view.SetText(text).ScrollToEnd()
+		})
+	}()
+}
+
+func (browser *Browser) describe() {
+	it, ok := browser.current(browser.list.GetCurrentItem())
+	if !ok {
+		return
+	}
+	data, err := json.MarshalIndent(it.raw, "", "  ")
+	if err != nil {
+		return
+	}
+	browser.popup(utils.Label("tui.describe")+": "+it.name, string(data))
+}
+
+func (browser *Browser) copyArn() {
+	it, ok := browser.current(browser.list.GetCurrentItem())
+	if !ok {
+		return
+	}
+	browser.screen.SetClipboard([]byte(it.arn))
+	browser.setFooter(utils.Label("tui.copied") + ": " + it.arn)
+}
+
+func (browser *Browser) popup(title string, text string) *tview.TextView {
+	view := tview.NewTextView().SetScrollable(true)
+	view.SetText(text)
+	view.SetBorder(true).SetTitle(" " + title + " ")
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' {
+			browser.pages.RemovePage("popup")
+			browser.app.SetFocus(browser.list)
+			return nil
+		}
+		return event
+	})
+	browser.pages.AddPage("popup", view, true, true)
+	browser.app.SetFocus(view)
+	return view
+}
+
+func (browser *Browser) current(index int) (item, bool) {
+	if index < 0 || index >= len(browser.shown) {
+		return item{}, false
+	}
+	return browser.shown[index], true
+}
+
+func (browser *Browser) setHeader() {
+	crumbs := []string{browser.title}
+	crumbs = append(crumbs, browser.nav.Crumbs()...)
+	browser.header.SetText("[::b]fexec[::-]  " + tview.Escape(strings.Join(crumbs, " / ")))
+}
+
+func (browser *Browser) setFooter(text string) {
+	browser.footer.SetText("[gray]" + tview.Escape(text))
+}
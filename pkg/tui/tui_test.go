@@ -0,0 +1,92 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/gajirou/fexec/pkg/tui"
+)
+
+func TestNavigator(t *testing.T) {
+	nav := tui.NewNavigator()
+	nav.Load(tui.LevelCluster)
+	if _, _, ok := nav.Back(); ok {
+		t.Error("クラスターの一覧から戻れています。")
+	}
+
+	steps := []struct {
+		name  string
+		index int
+		next  int
+	}{
+		{name: "main", index: 2, next: tui.LevelService},
+		{name: "api", index: 0, next: tui.LevelTask},
+		{name: "0123456789abcdef", index: 1, next: tui.LevelContainer},
+	}
+	for _, step := range steps {
+		next, done := nav.Enter(step.name, step.index)
+		if next != step.next || done {
+			t.Fatalf("次の階層が期待値と異なります：%d %v", next, done)
+		}
+		nav.Load(next)
+	}
+	if crumbs := nav.Crumbs(); len(crumbs) != 3 || crumbs[0] != "main" || crumbs[2] != "0123456789abcdef" {
+		t.Errorf("パンくずが期待値と異なります：%v", crumbs)
+	}
+	if _, done := nav.Enter("app", 0); !done {
+		t.Error("コンテナを選択しても完了していません。")
+	}
+	want := tui.Selection{Cluster: "main", Service: "api", Task: "0123456789abcdef", Container: "app"}
+	if got := nav.Selection(); got != want {
+		t.Errorf("選択結果が期待値と異なります：%+v", got)
+	}
+
+	level, cursor, ok := nav.Back()
+	if !ok || level != tui.LevelTask || cursor != 1 {
+		t.Errorf("戻り先が期待値と異なります：%d %d %v", level, cursor, ok)
+	}
+	nav.Load(level)
+	level, cursor, _ = nav.Back()
+	if level != tui.LevelService || cursor != 0 {
+		t.Errorf("戻り先が期待値と異なります：%d %d", level, cursor)
+	}
+	req := nav.Load(level)
+	if req.Selection.Cluster != "main" || req.Selection.Service != "" || req.Selection.Task != "" {
+		t.Errorf("戻った階層より下の選択が消去されていません：%+v", req.Selection)
+	}
+}
+
+func TestNavigatorCurrent(t *testing.T) {
+	cases := []struct {
+		name    string
+		move    func(nav *tui.Navigator)
+		current bool
+	}{
+		{name: "正常パターン:移動なし", move: func(nav *tui.Navigator) {}, current: true},
+		{name: "異常パターン:取得中に戻る", move: func(nav *tui.Navigator) {
+			level, _, _ := nav.Back()
+			nav.Load(level)
+		}},
+		{name: "異常パターン:取得中に再読み込み", move: func(nav *tui.Navigator) { nav.Load(nav.Level()) }},
+		{name: "異常パターン:戻って同じ階層に進む", move: func(nav *tui.Navigator) {
+			level, _, _ := nav.Back()
+			nav.Load(level)
+			next, _ := nav.Enter("other", 1)
+			nav.Load(next)
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			nav := tui.NewNavigator()
+			nav.Load(tui.LevelCluster)
+			next, _ := nav.Enter("main", 0)
+			req := nav.Load(next)
+			c.move(nav)
+			if nav.Current(req) != c.current {
+				t.Errorf("取得結果の採否が期待値と異なります：%v", !c.current)
+			}
+			if req.Selection.Cluster != "main" {
+				t.Errorf("取得要求の選択が移動の影響を受けています：%+v", req.Selection)
+			}
+		})
+	}
+}
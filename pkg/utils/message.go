@@ -2,45 +2,89 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
+const (
+	LangJa      = "ja"
+	LangEn      = "en"
+	defaultLang = LangJa
+)
+
+type bundle struct {
+	info  map[string]string
+	error map[string]string
+	label map[string]string
+}
+
 var (
-	infoMessage = map[string]string{
-		"INF001": "プロファイル情報が取得できないため処理を終了します。\n",
-		"INF002": "クラスターが選択されていないため処理を終了します。\n",
-		"INF003": "クラスターに紐づくサービスが存在しないため処理を終了します。\n",
-		"INF004": "サービスが選択されていないため処理を終了します。\n",
-		"INF005": "サービスに紐づくタスクが存在しないため処理を終了します。\n",
-		"INF006": "タスクが選択されていないため処理を終了します。\n",
-		"INF007": "タスクに紐づくコンテナが存在しないため処理を終了します。\n",
-		"INF008": "コンテナが選択されていないため処理を終了します。\n",
-		"INF009": "execute command が有効ではないタスクのため終了します。\n",
-	}
-	errorMessage = map[string]string{
-		"ERR001": "session-manager-plugin がインストールされていません、以下を確認しインストールください。\nhttps://docs.aws.amazon.com/ja_jp/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html\n",
-		"ERR002": "aws プロファイルの取得に失敗しました。\n",
-		"ERR003": "該当のプロファイルに紐づく ECS クラスターが存在しないか、クラスター情報の取得に失敗しました。\n",
-		"ERR004": "該当のクラスターに紐づくサービスの取得に失敗しました。\n",
-		"ERR005": "該当のサービスに紐づくタスク存在しないか、タスクの取得に失敗しました。\n",
-		"ERR006": "タスクに紐づくコンテナの取得に失敗しました。\n",
-		"ERR999": "予期せぬエラーが発生しました。\n",
+	bundles = map[string]bundle{
+		LangJa: jaBundle,
+		LangEn: enBundle,
 	}
+	lang  = defaultLang
 	color = map[string]string{
-		"default": "\x1b[30;0m",
+		"default": "\x1b[0m",
 		"red":     "\x1b[31;1m",
-		"green":   "\x1b[32;5m",
+		"green":   "\x1b[32m",
 	}
 )
 
+var colorEnabled = true
+
+func SetLang(value string) error {
+	if _, ok := bundles[value]; !ok {
+		return fmt.Errorf("unsupported language: %s", value)
+	}
+	lang = value
+	return nil
+}
+
+// LC_ALL、LC_MESSAGES、LANG の順に参照し、未設定または C・POSIX の場合は日本語とする
+func DetectLang(getenv func(string) string) string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := getenv(key)
+		if value == "" {
+			continue
+		}
+		if value == "C" || value == "POSIX" || strings.HasPrefix(value, "C.") {
+			return defaultLang
+		}
+		if strings.HasPrefix(value, LangJa) {
+			return LangJa
+		}
+		return LangEn
+	}
+	return defaultLang
+}
+
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+}
+
 func findMessage(label string) string {
+	if !colorEnabled {
+		if strings.Contains(label, "INF") {
+			return bundles[lang].info[label]
+		}
+		return bundles[lang].error[label]
+	}
 	if strings.Contains(label, "INF") {
-		return color["green"] + infoMessage[label] + color["default"]
+		return color["green"] + bundles[lang].info[label] + color["default"]
 	} else {
-		return color["red"] + errorMessage[label] + color["default"]
+		return color["red"] + bundles[lang].error[label] + color["default"]
+	}
+}
+
+func Message(label string, args ...interface{}) string {
+	if len(args) > 0 {
+		return fmt.Sprintf(findMessage(label), args...)
 	}
+	return findMessage(label)
 }
 
-func PrintMessage(label string) {
-	fmt.Printf("%s", findMessage(label))
+// 標準出力は一覧や JSON などの出力のみに使うため、メッセージは標準エラー出力に出力する
+func PrintMessage(label string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s", Message(label, args...))
 }
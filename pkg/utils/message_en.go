@@ -0,0 +1,151 @@
+package utils
+
+var enBundle = bundle{
+	info: map[string]string{
+		"INF001": "Exiting because no profile information is available.\n",
+		"INF002": "Exiting because no cluster was selected.\n",
+		"INF003": "Exiting because the cluster has no services.\n",
+		"INF004": "Exiting because no service was selected.\n",
+		"INF005": "Exiting because the service has no tasks.\n",
+		"INF006": "Exiting because no task was selected.\n",
+		"INF007": "Exiting because the task has no containers.\n",
+		"INF008": "Exiting because no container was selected.\n",
+		"INF010": "Recording the session to %s\n",
+		"INF011": "The S3 upload has not finished; it will be retried in the background on the next run.\n",
+		"INF012": "Exiting because no reason was entered.\n",
+		"INF013": "Disconnected from %s (duration: %s, exit status: %d).\n",
+		"INF014": "The session was disconnected; reconnecting to %s.\n",
+		"INF015": "Giving up reconnecting because task %s has stopped or been replaced.\n",
+		"INF016": "Giving up reconnecting after %d failed attempts.\n",
+		"INF017": "Supported escape sequences (only after a newline):\n %[1]s. - disconnect the session\n %[1]s# - show the target, identity and duration\n %[1]sC - open a command prompt\n %[1]s? - show this help\n %[1]s%[1]s - send the escape character\n",
+		"INF018": "Target: %s\nIdentity: %s\nDuration: %s\n",
+		"INF019": "Available commands:\n -L [local_port:]remote_port - start port forwarding to the connected task\n",
+		"INF020": "Port forwarding started: localhost:%s -> %s:%s\n",
+		"INF021": "Exiting because no target was selected.\n",
+		"INF022": "Exiting because there is no connection history.\n",
+		"INF023": "Task %s no longer exists; selecting from the running tasks.\n",
+		"INF024": "Exiting because the task has no container named %s.\n",
+		"INF025": "Saved bookmark %s.\n",
+		"INF026": "Removed bookmark %s.\n",
+		"INF027": "Copied %s to %s (%d bytes).\n",
+		"INF028": "The specified resource was not found.\n",
+		"INF029": "Selected %s automatically.\n",
+		"INF030": "Checking which shells are available in the container.\n",
+		"INF031": "Could not detect a shell; starting %s.\n",
+		"INF032": "Running the command on %d tasks (up to %d at a time).\n",
+		"INF033": "No task matches the selection strategy %s.\n",
+		"INF034": "No running task matches %s.\n",
+	},
+	error: map[string]string{
+		"ERR001": "session-manager-plugin is not installed. Install it by following:\nhttps://docs.aws.amazon.com/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html\n",
+		"ERR002": "Failed to load the AWS profile.\n",
+		"ERR003": "The profile has no ECS clusters, or failed to get the clusters.\n",
+		"ERR004": "Failed to get the services of the cluster.\n",
+		"ERR005": "The service has no tasks, or failed to get the tasks.\n",
+		"ERR006": "Failed to get the containers of the task.\n",
+		"ERR007": "Failed to create the session recording file.\n",
+		"ERR008": "Failed to write the audit log.\n",
+		"ERR009": "Failed to read the audit log.\n",
+		"ERR010": "Failed to save the files for the S3 upload.\n",
+		"ERR012": "The reason does not match the required format (%s).\n",
+		"ERR013": "Failed to start port forwarding: %v\n",
+		"ERR014": "The escape character must be a single character or none.\n",
+		"ERR015": "Failed to read the connection history.\n",
+		"ERR016": "Failed to save the connection history.\n",
+		"ERR017": "Usage: fexec bookmark add <name> [flags] | fexec bookmark list | fexec bookmark rm <name>\n",
+		"ERR018": "Failed to load the configuration: %v\n",
+		"ERR019": "Failed to write the configuration file.\n",
+		"ERR020": "Bookmark %s does not exist.\n",
+		"ERR021": "Task selection strategy %s is not supported.\n",
+		"ERR022": "Usage: fexec config view [flags]\n",
+		"ERR023": "You are not authorized to call the AWS API.\nRequired IAM action: %s\nFix: allow the action above in the IAM policy of your role or user.\n",
+		"ERR024": "The cluster does not exist.\nRequired IAM action: %s\nFix: check the cluster name, the region (-region) and the profile (-p).\n",
+		"ERR025": "Execute command is not enabled for the task.\nRequired IAM action: %s\nFix: enable enableExecuteCommand on the service (ecs:UpdateService) and restart the task.\n",
+		"ERR026": "The ExecuteCommandAgent of the task is not connected.\nRequired IAM action: %s\nFix: allow ssmmessages:CreateControlChannel, CreateDataChannel, OpenControlChannel and OpenDataChannel in the task role, check the route to SSM (VPC endpoints or NAT), then restart the task.\n",
+		"ERR027": "Your credentials have expired.\nRequired IAM action: %s\nFix: refresh your credentials, for example with aws sso login.\n",
+		"ERR028": "The AWS API request rate was exceeded.\nRequired IAM action: %s\nFix: wait a while and retry, or lower max_count in the configuration file.\n",
+		"ERR029": "The AWS API call failed.\nRequired IAM action: %s\nCause: %v\n",
+		"ERR030": "Error code: %s\nRequest ID: %s\nDetail: %v\n",
+		"ERR031": "Usage: fexec ls clusters | services -cluster <name> | tasks -cluster <name> [-service <name>] | containers -cluster <name> -task <id> [-o table|json|yaml] [-template <template>]\n",
+		"ERR032": "Invalid output format: %v\n",
+		"ERR033": "Failed to transfer the file: %v\n",
+		"ERR034": "Failed to get the logs: %v\n",
+		"ERR035": "Found %d problems.\n",
+		"ERR036": "session-manager-plugin %s does not support ECS Exec. Update it to %s or later.\n",
+		"ERR037": "Failed to save the detected shell.\n",
+		"ERR038": "Specify the command for -all-tasks after -- or with -command.\n",
+		"ERR039": "The command failed on %d of %d tasks.\n",
+		"ERR040": "Failed to search for tasks.\n",
+		"ERR041": "Invalid date (use the YYYY-MM-DD format): %s\n",
+		"ERR999": "An unexpected error occurred.\n",
+	},
+	label: map[string]string{
+		"cluster":   "Select the cluster:",
+		"service":   "Select the service:",
+		"task":      "Select the task ID:",
+		"container": "Select the container:",
+		"reason":    "This target is protected. Enter the reason or ticket ID:",
+		"find":      "Select the task to connect to:",
+		"find.skip": "Do not connect",
+		"history":   "Select a target from the history:",
+
+		"tui.clusters":         "Clusters",
+		"tui.services":         "Services",
+		"tui.tasks":            "Tasks",
+		"tui.containers":       "Containers",
+		"tui.details":          "Details",
+		"tui.filter":           "Filter: ",
+		"tui.loading":          "Loading...",
+		"tui.logs":             "Logs",
+		"tui.logs_unavailable": "Logs are only available when a container is selected",
+		"tui.describe":         "Describe",
+		"tui.copied":           "Copied the ARN",
+		"tui.keys":             "Enter:select/connect  Esc:back  /:filter  r:reload  e:connect  l:logs  d:describe  y:copy ARN  q:quit",
+
+		"help.usage":         "Usage: fexec [command] [flags]\nWithout a command, fexec runs exec.",
+		"help.commands":      "Commands:",
+		"help.more":          "Run fexec <command> -h for details of each command.",
+		"help.exec":          "Connect to a container and run a command",
+		"help.ls":            "List clusters, services, tasks or containers",
+		"help.cp":            "Copy files to or from a container",
+		"help.port-forward":  "Forward a container port to localhost",
+		"help.logs":          "Show the logs of a container",
+		"help.describe":      "Show the details of a cluster, service, task or container",
+		"help.find":          "Find a task by private IP, ENI ID or task ID prefix",
+		"help.doctor":        "Diagnose the environment required to connect",
+		"help.whoami":        "Show the credentials in use",
+		"help.version":       "Show the version",
+		"help.history":       "Connect to a target from the history",
+		"help.bookmark":      "Manage target bookmarks",
+		"help.audit":         "Show the audit log",
+		"help.config":        "Show the configuration",
+		"help.completion":    "Print the shell completion script",
+		"help.help":          "Show the usage",
+		"usage.exec":         "Usage: fexec [exec] [flags] [bookmark]",
+		"usage.ls":           "Usage: fexec ls <clusters|services|tasks|containers> [flags]",
+		"usage.cp":           "Usage: fexec cp [flags] <source> <destination> (container paths look like :/path)",
+		"usage.port-forward": "Usage: fexec port-forward [flags] [local_port:]remote_port",
+		"usage.logs":         "Usage: fexec logs [flags]",
+		"usage.describe":     "Usage: fexec describe -cluster <name> [-service <name> | -task <id> [-container <name>]] [flags]",
+		"usage.find":         "Usage: fexec find [flags] <private IP | ENI ID | task ID prefix>",
+		"usage.doctor":       "Usage: fexec doctor [flags]",
+		"usage.whoami":       "Usage: fexec whoami [flags]",
+		"usage.version":      "Usage: fexec version [flags]",
+		"usage.history":      "Usage: fexec history [flags]",
+		"usage.bookmark":     "Usage: fexec bookmark add <name> [flags] | fexec bookmark list | fexec bookmark rm <name>",
+		"usage.audit":        "Usage: fexec audit [flags]",
+		"usage.config":       "Usage: fexec config view [flags]",
+		"usage.completion":   "Usage: fexec completion bash|zsh|fish",
+		"usage.help":         "Usage: fexec help [command]",
+
+		"doctor.plugin":      "session-manager-plugin",
+		"doctor.config":      "Configuration file",
+		"doctor.credential":  "Credentials",
+		"doctor.region":      "Region",
+		"doctor.identity":    "Identity",
+		"doctor.ecs":         "ECS access",
+		"doctor.clusters":    "%d clusters",
+		"doctor.terminal":    "Terminal",
+		"doctor.no_terminal": "Not a terminal; the sequential prompts are used",
+	},
+}
@@ -0,0 +1,151 @@
+package utils
+
+var jaBundle = bundle{
+	info: map[string]string{
+		"INF001": "プロファイル情報が取得できないため処理を終了します。\n",
+		"INF002": "クラスターが選択されていないため処理を終了します。\n",
+		"INF003": "クラスターに紐づくサービスが存在しないため処理を終了します。\n",
+		"INF004": "サービスが選択されていないため処理を終了します。\n",
+		"INF005": "サービスに紐づくタスクが存在しないため処理を終了します。\n",
+		"INF006": "タスクが選択されていないため処理を終了します。\n",
+		"INF007": "タスクに紐づくコンテナが存在しないため処理を終了します。\n",
+		"INF008": "コンテナが選択されていないため処理を終了します。\n",
+		"INF010": "セッションを記録します：%s\n",
+		"INF011": "S3 へのアップロードが完了していないため、次回実行時にバックグラウンドで再送します。\n",
+		"INF012": "接続理由が入力されていないため処理を終了します。\n",
+		"INF013": "%s から切断しました（接続時間：%s、終了ステータス：%d）。\n",
+		"INF014": "セッションが切断されたため %s へ再接続します。\n",
+		"INF015": "タスク %s が停止または置き換えられたため再接続を中止します。\n",
+		"INF016": "再接続が %d 回失敗したため再接続を中止します。\n",
+		"INF017": "サポートしているエスケープシーケンス（改行直後のみ有効）：\n %[1]s. - セッションを切断\n %[1]s# - 接続先・利用者・接続時間を表示\n %[1]sC - コマンドプロンプトを開く\n %[1]s? - このヘルプを表示\n %[1]s%[1]s - エスケープ文字を送信\n",
+		"INF018": "接続先：%s\n利用者：%s\n接続時間：%s\n",
+		"INF019": "利用可能なコマンド：\n -L [ローカルポート:]リモートポート - 接続中のタスクへのポートフォワードを開始\n",
+		"INF020": "ポートフォワードを開始しました：localhost:%s -> %s:%s\n",
+		"INF021": "接続先が選択されていないため処理を終了します。\n",
+		"INF022": "接続履歴が存在しないため処理を終了します。\n",
+		"INF023": "タスク %s が存在しないため、稼働中のタスクから選択します。\n",
+		"INF024": "タスクにコンテナ %s が存在しないため処理を終了します。\n",
+		"INF025": "ブックマーク %s を保存しました。\n",
+		"INF026": "ブックマーク %s を削除しました。\n",
+		"INF027": "%s を %s にコピーしました（%d バイト）。\n",
+		"INF028": "指定したリソースが見つかりません。\n",
+		"INF029": "%s を自動で選択しました。\n",
+		"INF030": "コンテナで利用できるシェルを確認しています。\n",
+		"INF031": "シェルを判別できなかったため %s を起動します。\n",
+		"INF032": "%d 件のタスクでコマンドを実行します（同時実行数：%d）。\n",
+		"INF033": "選択方法 %s に一致するタスクが存在しないため処理を終了します。\n",
+		"INF034": "%s に一致する稼働中のタスクが見つかりません。\n",
+	},
+	error: map[string]string{
+		"ERR001": "session-manager-plugin がインストールされていません、以下を確認しインストールください。\nhttps://docs.aws.amazon.com/ja_jp/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html\n",
+		"ERR002": "aws プロファイルの取得に失敗しました。\n",
+		"ERR003": "該当のプロファイルに紐づく ECS クラスターが存在しないか、クラスター情報の取得に失敗しました。\n",
+		"ERR004": "該当のクラスターに紐づくサービスの取得に失敗しました。\n",
+		"ERR005": "該当のサービスに紐づくタスク存在しないか、タスクの取得に失敗しました。\n",
+		"ERR006": "タスクに紐づくコンテナの取得に失敗しました。\n",
+		"ERR007": "セッション記録ファイルの作成に失敗しました。\n",
+		"ERR008": "監査ログの書き込みに失敗しました。\n",
+		"ERR009": "監査ログの読み込みに失敗しました。\n",
+		"ERR010": "S3 アップロード用のファイルの保存に失敗しました。\n",
+		"ERR012": "接続理由が必要な形式（%s）と一致しません。\n",
+		"ERR013": "ポートフォワードの開始に失敗しました：%v\n",
+		"ERR014": "エスケープ文字は 1 文字または none を指定してください。\n",
+		"ERR015": "接続履歴の読み込みに失敗しました。\n",
+		"ERR016": "接続履歴の保存に失敗しました。\n",
+		"ERR017": "使い方：fexec bookmark add <名前> [パラメータ] | fexec bookmark list | fexec bookmark rm <名前>\n",
+		"ERR018": "設定の読み込みに失敗しました：%v\n",
+		"ERR019": "設定ファイルの書き込みに失敗しました。\n",
+		"ERR020": "ブックマーク %s が存在しません。\n",
+		"ERR021": "タスクの選択方法 %s はサポートしていません。\n",
+		"ERR022": "使い方：fexec config view [パラメータ]\n",
+		"ERR023": "AWS API を実行する権限がありません。\n必要な IAM アクション：%s\n対処：利用しているロールまたはユーザーの IAM ポリシーで上記のアクションを許可してください。\n",
+		"ERR024": "指定したクラスターが存在しません。\n必要な IAM アクション：%s\n対処：クラスター名・リージョン（-region）・プロファイル（-p）が正しいか確認してください。\n",
+		"ERR025": "タスクで execute command が有効になっていません。\n必要な IAM アクション：%s\n対処：サービスの enableExecuteCommand を有効にし（ecs:UpdateService）、タスクを再起動してください。\n",
+		"ERR026": "タスクの ExecuteCommandAgent が接続されていません。\n必要な IAM アクション：%s\n対処：タスクロールで ssmmessages:CreateControlChannel・CreateDataChannel・OpenControlChannel・OpenDataChannel を許可し、SSM への経路（VPC エンドポイントまたは NAT）を確認した上でタスクを再起動してください。\n",
+		"ERR027": "認証情報の有効期限が切れています。\n必要な IAM アクション：%s\n対処：aws sso login などで認証情報を更新してください。\n",
+		"ERR028": "AWS API の呼び出し回数が制限を超えました。\n必要な IAM アクション：%s\n対処：しばらく待ってから再実行するか、設定ファイルの max_count を小さくしてください。\n",
+		"ERR029": "AWS API の呼び出しに失敗しました。\n必要な IAM アクション：%s\n原因：%v\n",
+		"ERR030": "エラーコード：%s\nリクエスト ID：%s\n詳細：%v\n",
+		"ERR031": "使い方：fexec ls clusters | services -cluster <名前> | tasks -cluster <名前> [-service <名前>] | containers -cluster <名前> -task <ID> [-o table|json|yaml] [-template <テンプレート>]\n",
+		"ERR032": "出力形式の指定が不正です：%v\n",
+		"ERR033": "ファイルの転送に失敗しました：%v\n",
+		"ERR034": "ログの取得に失敗しました：%v\n",
+		"ERR035": "%d 件の問題が見つかりました。\n",
+		"ERR036": "session-manager-plugin %s は ECS Exec に対応していません。%s 以降に更新してください。\n",
+		"ERR037": "シェルの判別結果の保存に失敗しました。\n",
+		"ERR038": "-all-tasks では -- の後、または -command で実行するコマンドを指定してください。\n",
+		"ERR039": "%d / %d 件のタスクでコマンドが失敗しました。\n",
+		"ERR040": "タスクの検索に失敗しました。\n",
+		"ERR041": "日付の形式が不正です（YYYY-MM-DD 形式で指定してください）：%s\n",
+		"ERR999": "予期せぬエラーが発生しました。\n",
+	},
+	label: map[string]string{
+		"cluster":   "対象のクラスター名を選択してください：",
+		"service":   "対象のサービス名を選択してください：",
+		"task":      "対象のタスク ID を選択してください：",
+		"container": "対象のコンテナを選択してください：",
+		"reason":    "保護対象のため接続理由またはチケット ID を入力してください：",
+		"find":      "接続するタスクを選択してください：",
+		"find.skip": "接続しない",
+		"history":   "接続先を履歴から選択してください：",
+
+		"tui.clusters":         "クラスター",
+		"tui.services":         "サービス",
+		"tui.tasks":            "タスク",
+		"tui.containers":       "コンテナ",
+		"tui.details":          "詳細",
+		"tui.filter":           "絞り込み：",
+		"tui.loading":          "読み込み中...",
+		"tui.logs":             "ログ",
+		"tui.logs_unavailable": "ログはコンテナを選択中のみ表示できます",
+		"tui.describe":         "詳細",
+		"tui.copied":           "ARN をコピーしました",
+		"tui.keys":             "Enter:選択/接続  Esc:戻る  /:絞り込み  r:再読み込み  e:接続  l:ログ  d:詳細  y:ARN コピー  q:終了",
+
+		"help.usage":         "使い方：fexec [コマンド] [パラメータ]\nコマンドを省略した場合は exec として動作します。",
+		"help.commands":      "コマンド：",
+		"help.more":          "各コマンドの詳細は fexec <コマンド> -h で確認できます。",
+		"help.exec":          "コンテナに接続してコマンドを実行",
+		"help.ls":            "クラスター・サービス・タスク・コンテナを一覧表示",
+		"help.cp":            "コンテナとの間でファイルをコピー",
+		"help.port-forward":  "コンテナのポートをローカルに転送",
+		"help.logs":          "コンテナのログを表示",
+		"help.describe":      "クラスター・サービス・タスク・コンテナの詳細を表示",
+		"help.find":          "プライベート IP・ENI ID・タスク ID の一部からタスクを検索",
+		"help.doctor":        "接続に必要な環境を診断",
+		"help.whoami":        "利用中の認証情報を表示",
+		"help.version":       "バージョンを表示",
+		"help.history":       "接続履歴から選択して接続",
+		"help.bookmark":      "接続先のブックマークを管理",
+		"help.audit":         "監査ログを表示",
+		"help.config":        "設定を表示",
+		"help.completion":    "シェルの補完スクリプトを出力",
+		"help.help":          "使い方を表示",
+		"usage.exec":         "使い方：fexec [exec] [パラメータ] [ブックマーク名]",
+		"usage.ls":           "使い方：fexec ls <clusters|services|tasks|containers> [パラメータ]",
+		"usage.cp":           "使い方：fexec cp [パラメータ] <コピー元> <コピー先>（コンテナのパスは :/path のように指定）",
+		"usage.port-forward": "使い方：fexec port-forward [パラメータ] [ローカルポート:]リモートポート",
+		"usage.logs":         "使い方：fexec logs [パラメータ]",
+		"usage.describe":     "使い方：fexec describe -cluster <名前> [-service <名前> | -task <ID> [-container <名前>]] [パラメータ]",
+		"usage.find":         "使い方：fexec find [パラメータ] <プライベート IP | ENI ID | タスク ID の先頭>",
+		"usage.doctor":       "使い方：fexec doctor [パラメータ]",
+		"usage.whoami":       "使い方：fexec whoami [パラメータ]",
+		"usage.version":      "使い方：fexec version [パラメータ]",
+		"usage.history":      "使い方：fexec history [パラメータ]",
+		"usage.bookmark":     "使い方：fexec bookmark add <名前> [パラメータ] | fexec bookmark list | fexec bookmark rm <名前>",
+		"usage.audit":        "使い方：fexec audit [パラメータ]",
+		"usage.config":       "使い方：fexec config view [パラメータ]",
+		"usage.completion":   "使い方：fexec completion bash|zsh|fish",
+		"usage.help":         "使い方：fexec help [コマンド]",
+
+		"doctor.plugin":      "session-manager-plugin",
+		"doctor.config":      "設定ファイル",
+		"doctor.credential":  "認証情報",
+		"doctor.region":      "リージョン",
+		"doctor.identity":    "認証",
+		"doctor.ecs":         "ECS へのアクセス",
+		"doctor.clusters":    "%d 件のクラスター",
+		"doctor.terminal":    "端末",
+		"doctor.no_terminal": "端末ではないため順番に選択するプロンプトを利用します",
+	},
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var messageKey = regexp.MustCompile(`^(INF|ERR)\d{3}$`)
+
+func TestBundleKeys(t *testing.T) {
+	for name, b := range bundles {
+		for other, o := range bundles {
+			for key := range b.info {
+				if _, ok := o.info[key]; !ok {
+					t.Errorf("%s にあるメッセージ %s が %s にありません。", name, key, other)
+				}
+			}
+			for key := range b.error {
+				if _, ok := o.error[key]; !ok {
+					t.Errorf("%s にあるメッセージ %s が %s にありません。", name, key, other)
+				}
+			}
+			for key := range b.label {
+				if _, ok := o.label[key]; !ok {
+					t.Errorf("%s にあるラベル %s が %s にありません。", name, key, other)
+				}
+			}
+		}
+	}
+}
+
+func TestUsedKeys(t *testing.T) {
+	messages, labels := usedKeys(t, filepath.Join("..", ".."))
+	if len(messages) <= 0 || len(labels) <= 0 {
+		t.Fatal("ソースコードからキーを取得できません。")
+	}
+	for name, b := range bundles {
+		for key := range messages {
+			if b.info[key] == "" && b.error[key] == "" {
+				t.Errorf("%s にメッセージ %s がありません。", name, key)
+			}
+		}
+		for key := range labels {
+			if b.label[key] == "" {
+				t.Errorf("%s にラベル %s がありません。", name, key)
+			}
+		}
+	}
+}
+
+func TestDetectLang(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{}, LangJa},
+		{map[string]string{"LANG": "C"}, LangJa},
+		{map[string]string{"LANG": "ja_JP.UTF-8"}, LangJa},
+		{map[string]string{"LANG": "en_US.UTF-8"}, LangEn},
+		{map[string]string{"LANG": "ja_JP.UTF-8", "LC_ALL": "en_GB.UTF-8"}, LangEn},
+		{map[string]string{"LANG": "en_US.UTF-8", "LC_MESSAGES": "ja_JP.UTF-8"}, LangJa},
+	}
+	for _, c := range cases {
+		if got := DetectLang(func(key string) string { return c.env[key] }); got != c.want {
+			t.Errorf("%v の言語が期待値と異なります：%s", c.env, got)
+		}
+	}
+	if err := SetLang("fr"); err == nil {
+		t.Error("未対応の言語がエラーになっていません。")
+	}
+}
+
+// ScreenDraw・ScreenInput・Label に渡すラベルと、メッセージコードをソースコードから集める
+func usedKeys(t *testing.T, root string) (map[string]bool, map[string]bool) {
+	messages := map[string]bool{}
+	labels := map[string]bool{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BasicLit:
+				if value, ok := stringLit(n); ok {
+					if messageKey.MatchString(value) {
+						messages[value] = true
+					} else if strings.HasPrefix(value, "tui.") {
+						labels[value] = true
+					}
+				}
+			case *ast.CallExpr:
+				index := -1
+				switch callName(n) {
+				case "ScreenDraw":
+					index = 1
+				case "ScreenInput", "Label":
+					index = 0
+				}
+				if index >= 0 && index < len(n.Args) {
+					if lit, ok := n.Args[index].(*ast.BasicLit); ok {
+						if value, ok := stringLit(lit); ok {
+							labels[value] = true
+						}
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messages, labels
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+func stringLit(lit *ast.BasicLit) (string, bool) {
+	if lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
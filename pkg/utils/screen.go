@@ -5,15 +5,20 @@ import (
 	"github.com/AlecAivazis/survey/v2/terminal"
 )
 
-var (
-	labelMessage = map[string]string{
-		"cluster":   "対象のクラスター名を選択してください：",
-		"service":   "対象のサービス名を選択してください：",
-		"task":      "対象のタスク ID を選択してください：",
-		"container": "対象のコンテナを選択してください：",
-	}
+const (
+	DefaultPageSize = 7
 )
 
+var pageSize = DefaultPageSize
+
+func SetPageSize(size int) {
+	pageSize = size
+}
+
+func Label(key string) string {
+	return bundles[lang].label[key]
+}
+
 var answers struct {
 	Askone string `survey:"askone"`
 }
@@ -23,9 +28,10 @@ func ScreenDraw(options []string, label string) (string, error) {
 		{
 			Name: "askone",
 			Prompt: &survey.Select{
-				Message: labelMessage[label],
-				Options: options,
-				Default: options[0],
+				Message:  Label(label),
+				Options:  options,
+				Default:  options[0],
+				PageSize: pageSize,
 			},
 			Validate: survey.Required,
 		},
@@ -39,3 +45,21 @@ func ScreenDraw(options []string, label string) (string, error) {
 	}
 	return answers.Askone, nil
 }
+
+func ScreenInput(label string, validate func(string) error) (string, error) {
+	var answer string
+	err := survey.AskOne(
+		&survey.Input{Message: Label(label)},
+		&answer,
+		survey.WithValidator(func(ans interface{}) error {
+			return validate(ans.(string))
+		}),
+	)
+	if err != nil {
+		if err == terminal.InterruptErr {
+			return "", nil
+		}
+		return "", err
+	}
+	return answer, nil
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	appName = "fexec"
+)
+
+func StateDir() string {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+func CacheDir() string {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+func ConfigDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+func xdgDir(env string, fallback string) string {
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(home, fallback, appName)
+}
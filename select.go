@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/tui"
+	"github.com/gajirou/fexec/pkg/utils"
+	"golang.org/x/term"
+)
+
+const logLimit = 200
+
+type target struct {
+	Cluster   string
+	Service   string
+	Task      string
+	Container string
+}
+
+type tuiSource struct {
+	*awshelper.EcsService
+	logs awshelper.LogsService
+}
+
+func (source *tuiSource) ContainerLogs(cluster string, task string, container string) ([]string, error) {
+	stream, err := source.GetLogStream(cluster, task, container)
+	if err != nil {
+		return nil, err
+	}
+	events, err := source.logs.GetLogEvents(stream, logLimit)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0, len(events))
+	for _, e := range events {
+		lines = append(lines, formatLogEvent(e))
+	}
+	return lines, nil
+}
+
+func formatLogEvent(e awshelper.LogEvent) string {
+	return fmt.Sprintf("%s %s", e.Timestamp.Local().Format(time.DateTime), e.Message)
+}
+
+func selectTarget(ecsService *awshelper.EcsService, awsConfig aws.Config, opts *options) (target, error) {
+	if opts.simple || opts.target != (target{}) || !canUseTui() {
+		return selectSimple(ecsService, opts)
+	}
+	source := &tuiSource{EcsService: ecsService}
+	source.logs.SetLogsClient(awsConfig)
+	browser := tui.NewBrowser(source, awsConfig.Region)
+	browser.Auto = !opts.noAuto
+	browser.Sidecars = opts.config.Sidecars
+	selection, ok, err := browser.Run()
+	if err != nil {
+		utils.PrintMessage("ERR999")
+		return target{}, err
+	}
+	if !ok {
+		utils.PrintMessage("INF021")
+		return target{}, errCancelled
+	}
+	return target{
+		Cluster:   selection.Cluster,
+		Service:   selection.Service,
+		Task:      selection.Task,
+		Container: selection.Container,
+	}, nil
+}
+
+// 候補が一つの場合は選択を省略する
+func screenDraw(options []string, label string, auto bool) (string, error) {
+	if auto && len(options) == 1 {
+		utils.PrintMessage("INF029", options[0])
+		return options[0], nil
+	}
+	return utils.ScreenDraw(options, label)
+}
+
+// 選択方法に条件が含まれる場合は、タスクの情報を取得して絞り込んでから選ぶ
+func pickTask(ecsService *awshelper.EcsService, cluster string, service string, tasks []string, strategy string, auto bool) (string, error) {
+	pick, err := awshelper.ParsePick(strategy)
+	if err != nil {
+		utils.PrintMessage("ERR021", strategy)
+		return "", err
+	}
+	if pick.Revision == awshelper.LatestRev && service != "" {
+		revision, err := ecsService.PrimaryRevision(cluster, service)
+		if err != nil {
+			utils.PrintMessage("ERR004")
+			return "", err
+		}
+		if revision != "" {
+			pick.Revision = revision
+		}
+	}
+	if pick.NeedsDetails() {
+		infos, err := ecsService.DescribeTaskInfos(cluster, tasks)
+		if err != nil {
+			utils.PrintMessage("ERR005")
+			return "", err
+		}
+		tasks = nil
+		for _, t := range pick.Filter(infos) {
+			tasks = append(tasks, t.Id)
+		}
+		if len(tasks) <= 0 {
+			utils.PrintMessage("INF033", strategy)
+			return "", errNotFound
+		}
+	}
+	switch pick.Order {
+	case awshelper.PickPrompt:
+		return screenDraw(tasks, "task", auto)
+	case awshelper.PickRandom:
+		return tasks[rand.IntN(len(tasks))], nil
+	default:
+		return tasks[0], nil
+	}
+}
+
+func canUseTui() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func selectSimple(ecsService *awshelper.EcsService, opts *options) (target, error) {
+	preset, auto := opts.target, !opts.noAuto
+	cluster := preset.Cluster
+	if cluster == "" {
+		clusters, err := ecsService.GetClusters()
+		if err != nil {
+			utils.PrintMessage("ERR003")
+			return target{}, err
+		}
+		cluster, err = screenDraw(clusters, "cluster", auto)
+		if err != nil {
+			utils.PrintMessage("ERR999")
+			return target{}, err
+		}
+		if cluster == "" {
+			utils.PrintMessage("INF002")
+			return target{}, errCancelled
+		}
+	}
+
+	// タスク ID が指定されている場合はサービスの選択を省略する
+	service := preset.Service
+	if service == "" && preset.Task == "" {
+		services, err := ecsService.GetServices(cluster)
+		if err != nil {
+			utils.PrintMessage("ERR004")
+			return target{}, err
+		}
+		if services == nil {
+			utils.PrintMessage("INF003")
+			return target{}, errNotFound
+		}
+		service, err = screenDraw(services, "service", auto)
+		if err != nil {
+			utils.PrintMessage("ERR999")
+			return target{}, err
+		}
+		if service == "" {
+			utils.PrintMessage("INF004")
+			return target{}, errCancelled
+		}
+	}
+
+	tasks, err := ecsService.GetTasks(cluster, service)
+	if err != nil {
+		utils.PrintMessage("ERR005")
+		return target{}, err
+	}
+	if tasks == nil {
+		utils.PrintMessage("INF005")
+		return target{}, errNotFound
+	}
+	task := preset.Task
+	if task != "" && !slices.Contains(tasks, task) {
+		utils.PrintMessage("INF023", task)
+		task = ""
+	}
+	if task == "" {
+		task, err = pickTask(ecsService, cluster, service, tasks, opts.pick, auto)
+		if err != nil {
+			return target{}, err
+		}
+		if task == "" {
+			utils.PrintMessage("INF006")
+			return target{}, errCancelled
+		}
+	}
+
+	infos, err := ecsService.ListContainerInfos(cluster, task)
+	if err != nil {
+		utils.PrintMessage("ERR006")
+		return target{}, err
+	}
+	if infos == nil {
+		utils.PrintMessage("INF007")
+		return target{}, errNotFound
+	}
+	containers := make([]string, 0, len(infos))
+	for _, c := range infos {
+		containers = append(containers, c.Name)
+	}
+	container := preset.Container
+	if container != "" && !slices.Contains(containers, container) {
+		utils.PrintMessage("INF024", container)
+		return target{}, errNotFound
+	}
+	// サイドカーを除いたアプリケーションのコンテナが一つに決まる場合は選択を省略する
+	if container == "" && auto {
+		if app, ok := awshelper.AppContainer(infos, opts.config.Sidecars); ok {
+			utils.PrintMessage("INF029", app)
+			container = app
+		}
+	}
+	if container == "" {
+		container, err = screenDraw(containers, "container", auto)
+		if err != nil {
+			utils.PrintMessage("ERR999")
+			return target{}, err
+		}
+		if container == "" {
+			utils.PrintMessage("INF008")
+			return target{}, errCancelled
+		}
+	}
+
+	return target{Cluster: cluster, Service: service, Task: task, Container: container}, nil
+}
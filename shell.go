@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/probe"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+func findShellCacheFile() string {
+	return filepath.Join(utils.CacheDir(), "shells.json")
+}
+
+// プラットフォームを取得できない場合は Linux として扱う
+func newRemoteCommand(plugin string, awsConfig aws.Config, ecsService *awshelper.EcsService, tgt target) *remoteCommand {
+	platform, _ := ecsService.GetTaskPlatform(tgt.Cluster, tgt.Task)
+	return newPlatformCommand(plugin, awsConfig, ecsService, tgt, platform)
+}
+
+// プラットフォームを判定済みのタスクに対して作成する
+func newPlatformCommand(plugin string, awsConfig aws.Config, ecsService *awshelper.EcsService, tgt target, platform awshelper.TaskPlatform) *remoteCommand {
+	return &remoteCommand{
+		plugin:     plugin,
+		awsConfig:  awsConfig,
+		ecsService: ecsService,
+		target:     tgt,
+		platform:   platform,
+		shell:      awshelper.DefaultShell,
+	}
+}
+
+// 終了コードを出力するスクリプトを付け、接続先の OS のシェルで実行するコマンドにする
+// 目印はコマンドごとに作り直し、セッションの出力から終了コードを取り出す際に使う
+func (remote *remoteCommand) command(script string) string {
+	remote.marker = session.NewExitMarker()
+	if remote.platform.Windows() {
+		return awshelper.PowerShellCommand(script + remote.marker.PowerShellScript())
+	}
+	return awshelper.ShellCommand(remote.shell, script+remote.marker.Script())
+}
+
+// スクリプト内の exit で終了コードの出力が省略されないよう、子プロセスのシェルで実行する
+func (remote *remoteCommand) isolate(script string) string {
+	if remote.platform.Windows() {
+		return awshelper.PowerShellCommand(script)
+	}
+	return awshelper.ShellCommand(remote.shell, script)
+}
+
+func (remote *remoteCommand) withEnv(command string, env map[string]string) string {
+	if remote.platform.Windows() {
+		return awshelper.PowerShellWithEnv(command, env)
+	}
+	return awshelper.CommandWithEnv(command, env)
+}
+
+func (remote *remoteCommand) joinArgs(args []string) string {
+	if remote.platform.Windows() {
+		return awshelper.PowerShellJoinArgs(args)
+	}
+	return awshelper.JoinArgs(args)
+}
+
+func (remote *remoteCommand) quote(s string) string {
+	if remote.platform.Windows() {
+		return awshelper.PowerShellQuote(s)
+	}
+	return awshelper.Quote(s)
+}
+
+// Windows では PowerShell を起動し、Linux ではコンテナで利用できるシェルを判別して起動する
+func (remote *remoteCommand) defaultCommand() string {
+	if remote.platform.Windows() {
+		return awshelper.WindowsShell
+	}
+	result := remote.resolveShell()
+	remote.shell = result.Wrapper
+	return probe.LoginCommand(result.Shell, os.Getenv("TERM"))
+}
+
+// 判別結果はタスク定義のリビジョンとコンテナごとに保存し、2 回目以降の接続では判別を省略する
+func (remote *remoteCommand) resolveShell() probe.Result {
+	fallback := probe.Result{Wrapper: awshelper.DefaultShell, Shell: awshelper.DefaultShell}
+	if remote.platform.TaskDefinitionArn == "" {
+		return fallback
+	}
+	store := probe.NewStore(findShellCacheFile())
+	key := probe.Key(remote.platform.TaskDefinitionArn, remote.target.Container)
+	if result, ok := store.Get(key); ok {
+		return result
+	}
+
+	utils.PrintMessage("INF030")
+	for _, wrapper := range probe.Wrappers {
+		var out bytes.Buffer
+		remote.shell = wrapper
+		// 判別中に手元の標準入力を読み込まないよう、空の入力を渡す
+		remote.run(probe.Script, strings.NewReader(""), &out)
+		if shell, ok := probe.Parse(out.String()); ok {
+			result := probe.Result{Wrapper: wrapper, Shell: shell}
+			if err := store.Set(key, result); err != nil {
+				utils.PrintMessage("ERR037")
+			}
+			return result
+		}
+	}
+	utils.PrintMessage("INF031", awshelper.DefaultShell)
+	return fallback
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/gajirou/fexec/pkg/audit"
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/sink"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+const (
+	uploadTimeout = 5 * time.Second
+)
+
+func newS3Sink(awsConfig aws.Config, s3Config config.S3) *sink.Sink {
+	if s3Config.Bucket == "" {
+		return nil
+	}
+	s3Service := awshelper.S3Service{
+		Bucket:   s3Config.Bucket,
+		Prefix:   s3Config.Prefix,
+		KmsKeyId: s3Config.KmsKeyId,
+	}
+	s3Service.SetS3Client(awsConfig, s3Config.Endpoint)
+	s3Sink := sink.NewSink(&s3Service, filepath.Join(utils.StateDir(), "spool"))
+	s3Sink.Start()
+	return s3Sink
+}
+
+// 監査ログへの記録と S3 への送信をまとめて行う。並列に実行したセッションからも呼び出す
+type auditor struct {
+	log      audit.Log
+	sink     *sink.Sink
+	identity awshelper.Identity
+	profile  string
+	region   string
+	reason   string
+	mu       sync.Mutex
+}
+
+// 接続理由を確定した後に作成する
+func newAuditor(awsConfig aws.Config, opts *options) *auditor {
+	stsService := awshelper.StsService{}
+	stsService.SetStsClient(awsConfig)
+	identity, err := stsService.GetCallerIdentity()
+	if err != nil {
+		identity.Arn = "unknown"
+	}
+	return &auditor{
+		log:      audit.NewLog(opts.config.Audit.Log),
+		sink:     newS3Sink(awsConfig, opts.config.Audit.S3),
+		identity: identity,
+		profile:  awshelper.ResolveProfile(opts.profile),
+		region:   awsConfig.Region,
+		reason:   opts.reason,
+	}
+}
+
+func (a *auditor) record(entry audit.Entry, sessionId string, castFile string) {
+	entry.Identity = a.identity.Arn
+	entry.Profile = a.profile
+	entry.Region = a.region
+	entry.Reason = a.reason
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.log.Append(entry); err != nil {
+		utils.PrintMessage("ERR008")
+	}
+	if a.sink == nil {
+		return
+	}
+	// セッションを開始できなかった場合も他のタスクとキーが重ならないよう、タスクと開始時刻から作る
+	if sessionId == "" {
+		sessionId = path.Base(entry.TaskArn) + "-" + strconv.FormatInt(entry.StartTime.UnixNano(), 10)
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		err = a.sink.EnqueueData(sink.Key(a.identity.Account, entry.Cluster, entry.StartTime, sessionId, ".json"), line)
+	}
+	if err == nil && castFile != "" {
+		err = a.sink.EnqueueFile(sink.Key(a.identity.Account, entry.Cluster, entry.StartTime, sessionId, ".cast"), castFile)
+	}
+	if err != nil {
+		utils.PrintMessage("ERR010")
+	}
+}
+
+// 送信の完了を待ち、時間内に終わらない場合は次回の起動時に再送する
+func (a *auditor) wait() {
+	if a.sink != nil && !a.sink.Wait(uploadTimeout) {
+		utils.PrintMessage("INF011")
+	}
+}
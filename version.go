@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/session"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+// リリース時は goreleaser の ldflags で埋め込む
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+type versionInfo struct {
+	Version       string `json:"version" yaml:"version"`
+	Commit        string `json:"commit" yaml:"commit"`
+	BuildDate     string `json:"buildDate" yaml:"buildDate"`
+	GoVersion     string `json:"goVersion" yaml:"goVersion"`
+	Platform      string `json:"platform" yaml:"platform"`
+	PluginPath    string `json:"pluginPath" yaml:"pluginPath"`
+	PluginVersion string `json:"pluginVersion" yaml:"pluginVersion"`
+}
+
+func runVersion(cfg config.Config, args []string) error {
+	flags, opts := newVersionFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	printer := opts.printer()
+	if err := printer.Validate(); err != nil {
+		utils.PrintMessage("ERR032", err)
+		return err
+	}
+
+	info := buildVersion()
+	if path, err := exec.LookPath(ssmPlugin); err == nil {
+		info.PluginPath = path
+		info.PluginVersion, _ = session.PluginVersion(path)
+	}
+	if err := printer.PrintFields(os.Stdout, info); err != nil {
+		return err
+	}
+	if info.PluginPath == "" {
+		utils.PrintMessage("ERR001")
+	} else if !session.SupportsEcsExec(info.PluginVersion) {
+		utils.PrintMessage("ERR036", info.PluginVersion, session.MinPluginVersion)
+	}
+	return nil
+}
+
+func newVersionFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("version")
+	opts := globalOptions(flags, cfg)
+	return flags, opts
+}
+
+// ldflags で埋め込まれていない場合は go install 時のモジュールと VCS の情報を使う
+func buildVersion() versionInfo {
+	info := versionInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	modified := false
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && commit == "" {
+		info.Commit += "-dirty"
+	}
+	return info
+}
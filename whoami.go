@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+
+	"github.com/gajirou/fexec/pkg/awshelper"
+	"github.com/gajirou/fexec/pkg/config"
+	"github.com/gajirou/fexec/pkg/utils"
+)
+
+type whoami struct {
+	Profile string `json:"profile" yaml:"profile"`
+	Region  string `json:"region" yaml:"region"`
+	Account string `json:"account" yaml:"account"`
+	Arn     string `json:"arn" yaml:"arn"`
+	UserId  string `json:"userId" yaml:"userId"`
+}
+
+func runWhoami(cfg config.Config, args []string) error {
+	flags, opts := newWhoamiFlags(cfg)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	printer := opts.printer()
+	if err := printer.Validate(); err != nil {
+		utils.PrintMessage("ERR032", err)
+		return err
+	}
+
+	awsConfig, err := loadAWSConfig(opts)
+	if err != nil {
+		return err
+	}
+	stsService := awshelper.StsService{}
+	stsService.SetStsClient(awsConfig)
+	identity, err := stsService.GetCallerIdentity()
+	if err != nil {
+		return err
+	}
+	return printer.PrintFields(os.Stdout, whoami{
+		Profile: awshelper.ResolveProfile(opts.profile),
+		Region:  awsConfig.Region,
+		Account: identity.Account,
+		Arn:     identity.Arn,
+		UserId:  identity.UserId,
+	})
+}
+
+func newWhoamiFlags(cfg config.Config) (*flag.FlagSet, *options) {
+	flags := newFlagSet("whoami")
+	opts := globalOptions(flags, cfg)
+	return flags, opts
+}